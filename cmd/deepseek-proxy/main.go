@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	api_keys := flag.String("keys", os.Getenv("DEEPSEEK_API_KEYS"), "comma separated DeepSeek api keys, defaults to $DEEPSEEK_API_KEYS or $DEEPSEEK_API_KEY")
+	tenants_path := flag.String("tenants", "", "JSON file with tenants and their virtual keys, the gateway is open when empty")
+	cache_size := flag.Int("cache-size", 0, "number of responses kept in the in-memory cache, 0 disables caching")
+	cache_dir := flag.String("cache-dir", "", "directory for the filesystem cache, overrides -cache-size")
+	cache_ttl := flag.Duration("cache-ttl", time.Hour, "time to live of cached responses")
+	flag.Parse()
+
+	if *api_keys == "" {
+		*api_keys = os.Getenv("DEEPSEEK_API_KEY")
+	}
+
+	keys := strings.Split(*api_keys, ",")
+	options := []deepseek_api.DeepSeekClientOptions{
+		deepseek_api.WithDeepSeekClientCommunication(deepseek_api.DEFAULT_PROTOCOL, deepseek_api.DEFAULT_HOST),
+		deepseek_api.WithDeepSeekClientHttpClient(&http.Client{}),
+		deepseek_api.WithDeepSeekClientDeduplication(true),
+	}
+	if len(keys) > 1 {
+		options = append(options, deepseek_api.WithDeepSeekClientKeyPool(deepseek_api.NewDeepSeekKeyPool(keys)))
+	} else {
+		options = append(options, deepseek_api.WithDeepSeekClientApi(keys[0]))
+	}
+
+	if *cache_dir != "" {
+		cache_store, err := deepseek_api.NewFileCacheStore(*cache_dir, *cache_ttl)
+		if err != nil {
+			log.Fatalf("open cache: %v", err)
+		}
+		options = append(options, deepseek_api.WithDeepSeekClientCache(cache_store, false))
+	} else if *cache_size > 0 {
+		options = append(options, deepseek_api.WithDeepSeekClientCache(deepseek_api.NewMemoryCacheStore(*cache_size, *cache_ttl), false))
+	}
+
+	client := deepseek_api.NewDeepSeekClient(options...)
+	if client == nil {
+		log.Fatal("no DeepSeek api key is configured")
+	}
+
+	proxy_options := []deepseek_api.DeepSeekProxyOptions{
+		deepseek_api.WithDeepSeekProxyLogger(func(entry deepseek_api.DeepSeekProxyLogEntry) {
+			log.Printf("%s %s tenant=%q model=%q stream=%v status=%d duration=%s tokens=%d err=%v",
+				entry.Method, entry.Path, entry.Tenant, entry.Model, entry.Stream, entry.StatusCode, entry.Duration, entry.Usage.TotalTokens, entry.Err)
+		}),
+	}
+
+	if *tenants_path != "" {
+		tenants_json, err := os.ReadFile(*tenants_path)
+		if err != nil {
+			log.Fatalf("read tenants: %v", err)
+		}
+
+		var tenants []deepseek_api.DeepSeekProxyTenant
+		err = json.Unmarshal(tenants_json, &tenants)
+		if err != nil {
+			log.Fatalf("parse tenants: %v", err)
+		}
+
+		for _, tenant := range tenants {
+			proxy_options = append(proxy_options, deepseek_api.WithDeepSeekProxyTenant(tenant))
+		}
+	}
+
+	proxy := deepseek_api.NewDeepSeekProxy(client, proxy_options...)
+
+	log.Printf("deepseek-proxy listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, proxy))
+}
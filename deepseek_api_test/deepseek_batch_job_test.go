@@ -0,0 +1,118 @@
+package deepseek_api_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestReadDeepSeekBatchJobs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "valid jobs",
+			input: `{"custom_id": "a", "method": "POST", "url": "/v1/chat/completions", "body": {"model": "deepseek-chat", "messages": [{"role": "user", "content": "Hi"}]}}
+
+{"custom_id": "b", "body": {"model": "deepseek-reasoner", "messages": [{"role": "user", "content": "Why?"}], "max_tokens": 100}}`,
+		},
+		{
+			name:     "missing custom id",
+			input:    `{"body": {"model": "deepseek-chat", "messages": [{"role": "user", "content": "Hi"}]}}`,
+			expected: "line 1: custom_id must be set",
+		},
+		{
+			name: "duplicate custom id",
+			input: `{"custom_id": "a", "body": {"model": "deepseek-chat", "messages": [{"role": "user", "content": "Hi"}]}}
+{"custom_id": "a", "body": {"model": "deepseek-chat", "messages": [{"role": "user", "content": "Hi"}]}}`,
+			expected: "line 2: custom_id a is already used on line 1",
+		},
+		{
+			name:     "unsupported url",
+			input:    `{"custom_id": "a", "url": "/v1/completions", "body": {"model": "deepseek-chat", "messages": [{"role": "user", "content": "Hi"}]}}`,
+			expected: "line 1: url /v1/completions is not supported",
+		},
+		{
+			name:     "invalid request",
+			input:    `{"custom_id": "a", "body": {"model": "deepseek-chat", "messages": [{"role": "user", "content": "Hi"}], "temperature": 3}}`,
+			expected: "line 1: temperature must be between 0 and 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs, err := deepseek_api.ReadDeepSeekBatchJobs(strings.NewReader(tt.input))
+			if tt.expected != "" {
+				if err == nil || err.Error() != tt.expected {
+					t.Errorf("Expected error %q, but got %v", tt.expected, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ReadDeepSeekBatchJobs error: %v", err)
+			}
+			if len(jobs) != 2 {
+				t.Fatalf("Expected 2 jobs, but got %d", len(jobs))
+			}
+			if jobs[0].Body.Temperature != 1 || jobs[0].Body.MaxTokens != 4096 {
+				t.Errorf("Expected request defaults, but got %+v", jobs[0].Body)
+			}
+			if jobs[1].Body.MaxTokens != 100 {
+				t.Errorf("Expected max_tokens 100, but got %d", jobs[1].Body.MaxTokens)
+			}
+		})
+	}
+}
+
+func TestDeepSeekBatchRunner_RunJobFile(t *testing.T) {
+	var calls []string
+	client := newTestBatchClient(t, &calls, map[string]bool{"broken": true})
+	runner := deepseek_api.NewDeepSeekBatchRunner(client)
+
+	dir := t.TempDir()
+	input_path := filepath.Join(dir, "jobs.jsonl")
+	output_path := filepath.Join(dir, "results.jsonl")
+	os.WriteFile(input_path, []byte(`{"custom_id": "first", "body": {"model": "deepseek-chat", "messages": [{"role": "user", "content": "hello"}]}}
+{"custom_id": "second", "body": {"model": "deepseek-chat", "messages": [{"role": "user", "content": "broken"}]}}
+`), 0o644)
+
+	usage, err := runner.RunJobFile(context.Background(), input_path, output_path)
+	if err != nil {
+		t.Fatalf("RunJobFile error: %v", err)
+	}
+	if usage.TotalTokens != 15 {
+		t.Errorf("Expected 15 total tokens, but got %d", usage.TotalTokens)
+	}
+
+	output, _ := os.Open(output_path)
+	defer output.Close()
+
+	var results []deepseek_api.DeepSeekBatchJobResult
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		result := deepseek_api.DeepSeekBatchJobResult{}
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		results = append(results, result)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, but got %d", len(results))
+	}
+	if results[0].CustomId != "first" || results[0].Response == nil || results[0].Usage == nil || results[0].Error != nil {
+		t.Errorf("Unexpected first result: %+v", results[0])
+	}
+	if results[1].CustomId != "second" || results[1].Error == nil || results[1].Error.StatusCode != 500 {
+		t.Errorf("Unexpected second result: %+v", results[1])
+	}
+}
@@ -0,0 +1,136 @@
+package deepseek_api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func newTestBatchRequests(t *testing.T, prompts ...string) []*deepseek_api.DeepSeekChatRequest {
+	requests := make([]*deepseek_api.DeepSeekChatRequest, 0, len(prompts))
+	for _, prompt := range prompts {
+		request, err := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User(prompt).Build()
+		if err != nil {
+			t.Fatalf("Build error: %v", err)
+		}
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+func newTestBatchClient(t *testing.T, calls *[]string, failing map[string]bool) *deepseek_api.DeepSeekClient {
+	var mutex sync.Mutex
+	return newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body := struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		prompt := body.Messages[0].Content
+
+		mutex.Lock()
+		*calls = append(*calls, prompt)
+		fail := failing[prompt]
+		mutex.Unlock()
+
+		if strings.HasPrefix(prompt, "slow") {
+			time.Sleep(30 * time.Millisecond)
+		}
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		writeTestChatResponse(w, "echo: "+prompt)
+	})
+}
+
+func TestDeepSeekBatchRunner_Run(t *testing.T) {
+	var calls []string
+	client := newTestBatchClient(t, &calls, map[string]bool{"broken": true})
+
+	var progress []deepseek_api.DeepSeekBatchProgress
+	runner := deepseek_api.NewDeepSeekBatchRunner(client,
+		deepseek_api.WithDeepSeekBatchRunnerConcurrency(3),
+		deepseek_api.WithDeepSeekBatchRunnerProgress(func(p deepseek_api.DeepSeekBatchProgress) {
+			progress = append(progress, p)
+		}),
+	)
+
+	prompts := []string{"slow one", "two", "broken", "slow four", "five"}
+	results, usage, err := runner.Run(context.Background(), newTestBatchRequests(t, prompts...))
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	if len(results) != len(prompts) {
+		t.Fatalf("Expected %d results, but got %d", len(prompts), len(results))
+	}
+	for i, result := range results {
+		if result.Index != i {
+			t.Errorf("Expected result %d to have index %d, but got %d", i, i, result.Index)
+		}
+		if prompts[i] == "broken" {
+			if result.Err == nil {
+				t.Errorf("Expected result %d to fail", i)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Errorf("Result %d: unexpected error %v", i, result.Err)
+		} else if content := result.Response.Choices[0].Message.Content; content != "echo: "+prompts[i] {
+			t.Errorf("Expected result %d content %q, but got %q", i, "echo: "+prompts[i], content)
+		}
+	}
+
+	if usage.TotalTokens != 60 {
+		t.Errorf("Expected 60 total tokens, but got %d", usage.TotalTokens)
+	}
+
+	last := progress[len(progress)-1]
+	if len(progress) != len(prompts) || last.Completed != 4 || last.Failed != 1 || last.Total != len(prompts) {
+		t.Errorf("Unexpected progress: %+v", last)
+	}
+}
+
+func TestDeepSeekBatchRunner_Checkpoint(t *testing.T) {
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+	prompts := []string{"one", "two", "three"}
+
+	var first_calls []string
+	first_client := newTestBatchClient(t, &first_calls, map[string]bool{"two": true})
+	first_runner := deepseek_api.NewDeepSeekBatchRunner(first_client, deepseek_api.WithDeepSeekBatchRunnerCheckpoint(checkpoint))
+	if _, _, err := first_runner.Run(context.Background(), newTestBatchRequests(t, prompts...)); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	var second_calls []string
+	second_client := newTestBatchClient(t, &second_calls, nil)
+	second_runner := deepseek_api.NewDeepSeekBatchRunner(second_client, deepseek_api.WithDeepSeekBatchRunnerCheckpoint(checkpoint))
+	results, usage, err := second_runner.Run(context.Background(), newTestBatchRequests(t, prompts...))
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	if len(second_calls) != 1 || second_calls[0] != "two" {
+		t.Errorf("Expected only the failed item to be retried, but got %v", second_calls)
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			t.Errorf("Result %d: unexpected error %v", i, result.Err)
+		}
+		if result.Resumed != (prompts[i] != "two") {
+			t.Errorf("Result %d: unexpected resumed flag %v", i, result.Resumed)
+		}
+	}
+	if usage.TotalTokens != 45 {
+		t.Errorf("Expected 45 total tokens including resumed results, but got %d", usage.TotalTokens)
+	}
+}
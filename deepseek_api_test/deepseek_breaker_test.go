@@ -0,0 +1,152 @@
+package deepseek_api_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestCircuitBreaker_Client(t *testing.T) {
+	var transitions []string
+	breaker := deepseek_api.NewCircuitBreaker(
+		deepseek_api.WithCircuitBreakerFailureThreshold(2),
+		deepseek_api.WithCircuitBreakerOpenTimeout(50*time.Millisecond),
+		deepseek_api.WithCircuitBreakerOnStateChange(func(from deepseek_api.CircuitState, to deepseek_api.CircuitState) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		}),
+	)
+
+	calls := 0
+	healthy := false
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if !healthy {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		writeTestChatResponse(w, "Hello")
+	})
+	client.SetCircuitBreaker(breaker)
+
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Chat(request); errors.Is(err, deepseek_api.ErrCircuitOpen) {
+			t.Fatalf("Expected request %d to reach the server, but got %v", i, err)
+		}
+	}
+
+	if _, err := client.Chat(request); !errors.Is(err, deepseek_api.ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, but got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls while open, but got %d", calls)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	healthy = true
+
+	if _, err := client.Chat(request); err != nil {
+		t.Fatalf("Expected half-open probe to succeed, but got %v", err)
+	}
+	if breaker.State() != deepseek_api.CIRCUIT_CLOSED {
+		t.Errorf("Expected breaker to be closed, but got %s", breaker.State())
+	}
+
+	expected := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(expected) {
+		t.Fatalf("Expected transitions %v, but got %v", expected, transitions)
+	}
+	for i := range expected {
+		if transitions[i] != expected[i] {
+			t.Errorf("Expected transition %q, but got %q", expected[i], transitions[i])
+		}
+	}
+}
+
+func TestCircuitBreaker_ClientErrorsDoNotTrip(t *testing.T) {
+	breaker := deepseek_api.NewCircuitBreaker(deepseek_api.WithCircuitBreakerFailureThreshold(1))
+
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	client.SetCircuitBreaker(breaker)
+
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Chat(request); errors.Is(err, deepseek_api.ErrCircuitOpen) {
+			t.Fatalf("Expected 400 responses not to open the breaker")
+		}
+	}
+}
+
+func TestCircuitBreaker_HalfOpenAllowsSingleProbe(t *testing.T) {
+	breaker := deepseek_api.NewCircuitBreaker(
+		deepseek_api.WithCircuitBreakerFailureThreshold(1),
+		deepseek_api.WithCircuitBreakerOpenTimeout(time.Millisecond),
+	)
+
+	breaker.Failure()
+	time.Sleep(2 * time.Millisecond)
+
+	if !breaker.Allow() {
+		t.Fatal("Expected first probe to be allowed")
+	}
+	if breaker.Allow() {
+		t.Error("Expected second probe to be rejected while half-open")
+	}
+
+	breaker.Failure()
+	if breaker.State() != deepseek_api.CIRCUIT_OPEN {
+		t.Errorf("Expected failed probe to reopen the breaker, but got %s", breaker.State())
+	}
+}
+
+func TestCircuitBreaker_ErrorRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []bool
+		expected deepseek_api.CircuitState
+	}{
+		{
+			name:     "below minimum requests",
+			outcomes: []bool{true, false, true},
+			expected: deepseek_api.CIRCUIT_CLOSED,
+		},
+		{
+			name:     "below error rate",
+			outcomes: []bool{true, false, false, false, true, false},
+			expected: deepseek_api.CIRCUIT_CLOSED,
+		},
+		{
+			name:     "at error rate",
+			outcomes: []bool{true, false, true, false, true, false},
+			expected: deepseek_api.CIRCUIT_OPEN,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker := deepseek_api.NewCircuitBreaker(
+				deepseek_api.WithCircuitBreakerFailureThreshold(100),
+				deepseek_api.WithCircuitBreakerErrorRate(0.5, time.Minute, 4),
+			)
+
+			for _, failed := range tt.outcomes {
+				if failed {
+					breaker.Failure()
+				} else {
+					breaker.Success()
+				}
+			}
+
+			if breaker.State() != tt.expected {
+				t.Errorf("Expected state %s, but got %s", tt.expected, breaker.State())
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package deepseek_api_test
+
+import (
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestDeepSeekChatBuilder_Build(t *testing.T) {
+	tool := deepseek_api.Tool{Type: "function"}
+	tool.Function.Name = "get_weather"
+
+	request, err := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).
+		System("You are a helpful assistant.").
+		User("How is the weather in Hangzhou?").
+		Temperature(0).
+		Stream(true).
+		Tools(tool).
+		Logprobs(5).
+		Build()
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+
+	if len(request.Messages) != 2 || request.Messages[0].GetRole() != deepseek_api.ROLE_SYSTEM {
+		t.Errorf("Unexpected messages: %v", request.Messages)
+	}
+	if !request.Stream || request.StreamOptions == nil || !request.StreamOptions.IncludeUsage {
+		t.Errorf("Expected stream options to be set with stream, but got %+v", request.StreamOptions)
+	}
+	if request.ToolChoice != deepseek_api.TOOL_CHOICE_AUTO {
+		t.Errorf("Expected tool_choice auto, but got %v", request.ToolChoice)
+	}
+	if !request.Logprobs || request.TopLogprobs == nil || *request.TopLogprobs != 5 {
+		t.Errorf("Expected logprobs to be set with top_logprobs")
+	}
+	if request.Temperature != 0 {
+		t.Errorf("Expected temperature 0, but got %v", request.Temperature)
+	}
+}
+
+func TestDeepSeekChatBuilder_ToolChoice(t *testing.T) {
+	tool := deepseek_api.Tool{Type: "function"}
+	tool.Function.Name = "get_weather"
+
+	tests := []struct {
+		name     string
+		builder  *deepseek_api.DeepSeekChatBuilder
+		expected deepseek_api.ToolChoice
+	}{
+		{"default", deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).Tools(tool), deepseek_api.TOOL_CHOICE_AUTO},
+		{"none before tools", deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).ToolChoice(deepseek_api.TOOL_CHOICE_NONE).Tools(tool), deepseek_api.TOOL_CHOICE_NONE},
+		{"none after tools", deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).Tools(tool).ToolChoice(deepseek_api.TOOL_CHOICE_NONE), deepseek_api.TOOL_CHOICE_NONE},
+		{"required before tools", deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).ToolChoice(deepseek_api.TOOL_CHOICE_REQUIRED).Tools(tool), deepseek_api.TOOL_CHOICE_REQUIRED},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := tt.builder.User("How is the weather in Hangzhou?").Build()
+			if err != nil {
+				t.Fatalf("Build error: %v", err)
+			}
+			if request.ToolChoice != tt.expected {
+				t.Errorf("Expected tool_choice %v, but got %v", tt.expected, request.ToolChoice)
+			}
+		})
+	}
+}
+
+func TestDeepSeekChatBuilder_Build_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		builder  *deepseek_api.DeepSeekChatBuilder
+		expected string
+	}{
+		{
+			name:     "No Messages",
+			builder:  deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT),
+			expected: "messages must be at least one",
+		},
+		{
+			name:     "Aggregated",
+			builder:  deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Temperature(3).TopP(2).ToolChoice(deepseek_api.TOOL_CHOICE_REQUIRED),
+			expected: "temperature must be between 0 and 2; top_p must be between 0 and 1; tools must be defined when tool_choice is required",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request, err := test.builder.Build()
+			if err == nil {
+				t.Fatalf("Expected error: %v, but got request %+v", test.expected, request)
+			}
+			if err.Error() != test.expected {
+				t.Errorf("Expected error: %v, but got: %v", test.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package deepseek_api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestDeepSeekClient_Cache(t *testing.T) {
+	tests := []struct {
+		name           string
+		temperature    float64
+		force          bool
+		expected_calls int
+	}{
+		{
+			name:           "deterministic request is cached",
+			temperature:    0,
+			expected_calls: 1,
+		},
+		{
+			name:           "sampled request bypasses cache",
+			temperature:    1,
+			expected_calls: 2,
+		},
+		{
+			name:           "forced cache",
+			temperature:    1,
+			force:          true,
+			expected_calls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				writeTestChatResponse(w, "positive")
+			})
+			client.SetCacheStore(deepseek_api.NewMemoryCacheStore(10, time.Minute), tt.force)
+
+			request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Classify: great").Temperature(tt.temperature).Build()
+
+			for i := 0; i < 2; i++ {
+				resp, err := client.Chat(request)
+				if err != nil {
+					t.Fatalf("Chat error: %v", err)
+				}
+				if resp.Choices[0].Message.Content != "positive" {
+					t.Errorf("Expected content %q, but got %q", "positive", resp.Choices[0].Message.Content)
+				}
+			}
+
+			if calls != tt.expected_calls {
+				t.Errorf("Expected %d calls, but got %d", tt.expected_calls, calls)
+			}
+		})
+	}
+}
+
+func TestDeepSeekClient_CacheKey(t *testing.T) {
+	store := deepseek_api.NewMemoryCacheStore(10, time.Minute)
+
+	var paths []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		writeTestChatResponse(w, r.URL.Path)
+	}
+
+	first := newTestDeepSeekClient(t, handler).SetCacheStore(store, false)
+	second := newTestDeepSeekClient(t, handler).SetCacheStore(store, false)
+
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Classify: great").Temperature(0).Build()
+
+	for _, client := range []*deepseek_api.DeepSeekClient{first, second} {
+		for _, path := range []string{deepseek_api.DEFAULT_CHAT_PATH, deepseek_api.DEFAULT_BETA_CHAT_PATH} {
+			for i := 0; i < 2; i++ {
+				ds_resp, err := client.Do(http.MethodPost, path, request)
+				if err != nil {
+					t.Fatalf("Do error: %v", err)
+				}
+
+				dsc_resp := ds_resp.(*deepseek_api.DeepSeekChatResponse)
+				if dsc_resp.Choices[0].Message.Content != path {
+					t.Errorf("Expected response for %s, but got %s", path, dsc_resp.Choices[0].Message.Content)
+				}
+			}
+		}
+	}
+
+	if len(paths) != 4 || store.Len() != 4 {
+		t.Errorf("Expected one upstream call and cache entry per endpoint and path, but got calls %v and %d entries", paths, store.Len())
+	}
+}
+
+func TestRequestHash(t *testing.T) {
+	user_request := deepseek_api.NewDeepSeekChatRequest([]deepseek_api.DeepSeekMessage{
+		&deepseek_api.UserMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_USER, Content: "Hello"}},
+	}, deepseek_api.MODEL_DEEPSEEK_CHAT)
+	basic_request := deepseek_api.NewDeepSeekChatRequest([]deepseek_api.DeepSeekMessage{
+		&deepseek_api.BasicMessage{Role: deepseek_api.ROLE_USER, Content: "Hello"},
+	}, deepseek_api.MODEL_DEEPSEEK_CHAT)
+
+	user_hash, err := deepseek_api.RequestHash(user_request)
+	if err != nil {
+		t.Fatalf("RequestHash error: %v", err)
+	}
+	basic_hash, _ := deepseek_api.RequestHash(basic_request)
+	if user_hash != basic_hash {
+		t.Errorf("Expected equivalent requests to hash equally, but got %s and %s", user_hash, basic_hash)
+	}
+
+	basic_request.Temperature = 0.5
+	changed_hash, _ := deepseek_api.RequestHash(basic_request)
+	if changed_hash == user_hash {
+		t.Error("Expected sampling parameters to change the hash")
+	}
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	store := deepseek_api.NewMemoryCacheStore(2, 0)
+	store.Set("a", []byte("1"))
+	store.Set("b", []byte("2"))
+	store.Get("a")
+	store.Set("c", []byte("3"))
+
+	if _, ok, _ := store.Get("b"); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if value, ok, _ := store.Get("a"); !ok || string(value) != "1" {
+		t.Errorf("Expected entry a to be kept, but got %q", value)
+	}
+	if store.Len() != 2 {
+		t.Errorf("Expected 2 entries, but got %d", store.Len())
+	}
+
+	expiring_store := deepseek_api.NewMemoryCacheStore(2, time.Millisecond)
+	expiring_store.Set("a", []byte("1"))
+	time.Sleep(2 * time.Millisecond)
+	if _, ok, _ := expiring_store.Get("a"); ok {
+		t.Error("Expected entry to expire")
+	}
+}
+
+func TestFileCacheStore(t *testing.T) {
+	store, err := deepseek_api.NewFileCacheStore(t.TempDir(), time.Minute)
+	if err != nil {
+		t.Fatalf("NewFileCacheStore error: %v", err)
+	}
+
+	if _, ok, err := store.Get("missing"); ok || err != nil {
+		t.Errorf("Expected miss without error, but got %v, %v", ok, err)
+	}
+
+	if err := store.Set("key", []byte(`{"id":"1"}`)); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	value, ok, err := store.Get("key")
+	if err != nil || !ok || string(value) != `{"id":"1"}` {
+		t.Errorf("Expected stored value, but got %q, %v, %v", value, ok, err)
+	}
+}
@@ -0,0 +1,143 @@
+package deepseek_api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestSentenceChunker_Write(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  []deepseek_api.SentenceChunkerOptions
+		expected []string
+	}{
+		{
+			name:     "english sentences",
+			input:    "Hello there! How are you? I am fine. Bye",
+			expected: []string{"Hello there!", "How are you?", "I am fine.", "Bye"},
+		},
+		{
+			name:     "decimal numbers",
+			input:    "Pi is 3.14 roughly. Done",
+			expected: []string{"Pi is 3.14 roughly.", "Done"},
+		},
+		{
+			name:     "closing quotes and repeated punctuation",
+			input:    `He said "stop!" Then what?! Nothing...`,
+			expected: []string{`He said "stop!"`, "Then what?!", "Nothing..."},
+		},
+		{
+			name:     "cjk sentences",
+			input:    "你好。今天天气怎么样？很好！“真的。”是的……",
+			expected: []string{"你好。", "今天天气怎么样？", "很好！", "“真的。”", "是的……"},
+		},
+		{
+			name:     "newlines",
+			input:    "- first item\n- second item\n",
+			expected: []string{"- first item", "- second item"},
+		},
+		{
+			name:     "fenced code block",
+			input:    "Run this. Then:\n```go\nfmt.Println(\"a. b! c?\")\n\nx := 1\n```\nDone.",
+			expected: []string{"Run this.", "Then:", "```go\nfmt.Println(\"a. b! c?\")\n\nx := 1\n```", "Done."},
+		},
+		{
+			name:     "unterminated code block",
+			input:    "Code:\n```\nline one. line two.",
+			expected: []string{"Code:", "```\nline one. line two."},
+		},
+		{
+			name:     "paragraphs",
+			input:    "First sentence. Second sentence.\nSame paragraph.\n\nNew paragraph. 还是。\n\n",
+			options:  []deepseek_api.SentenceChunkerOptions{deepseek_api.WithSentenceChunkerParagraphs(true)},
+			expected: []string{"First sentence. Second sentence.\nSame paragraph.", "New paragraph. 还是。"},
+		},
+		{
+			name:     "minimum length",
+			input:    "Hi. Ok. This is longer. End",
+			options:  []deepseek_api.SentenceChunkerOptions{deepseek_api.WithSentenceChunkerMinLength(8)},
+			expected: []string{"Hi. Ok. This is longer.", "End"},
+		},
+		{
+			name:     "custom terminators",
+			input:    "One; two. Three; four",
+			options:  []deepseek_api.SentenceChunkerOptions{deepseek_api.WithSentenceChunkerTerminators(";")},
+			expected: []string{"One;", "two. Three;", "four"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, size := range []int{1, 3, 1000} {
+				var chunks []string
+				chunker := deepseek_api.NewSentenceChunker(func(chunk string) { chunks = append(chunks, chunk) }, tt.options...)
+
+				runes := []rune(tt.input)
+				for i := 0; i < len(runes); i += size {
+					end := i + size
+					if end > len(runes) {
+						end = len(runes)
+					}
+					chunker.Write(string(runes[i:end]))
+				}
+				chunker.Flush()
+
+				if strings.Join(chunks, "|") != strings.Join(tt.expected, "|") {
+					t.Errorf("Expected chunks %q with delta size %d, but got %q", tt.expected, size, chunks)
+				}
+			}
+		})
+	}
+}
+
+func TestSentenceChunker_Wrap(t *testing.T) {
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeTestChatStream(w, "Hello wor", "ld. How", " are you? Fine")
+	})
+
+	var events []string
+	chunker := deepseek_api.NewSentenceChunker(func(chunk string) { events = append(events, "chunk:"+chunk) })
+	handler := chunker.Wrap(&deepseek_api.DeepSeekStreamHandler{
+		OnContent: func(delta string) { events = append(events, "content:"+delta) },
+		OnFinish: func(finish_reason string, usage *deepseek_api.Usage) {
+			events = append(events, fmt.Sprintf("finish:%d", usage.TotalTokens))
+		},
+	})
+
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+	client.StreamChat(context.Background(), request, handler)
+
+	expected := []string{"content:Hello wor", "content:ld. How", "chunk:Hello world.", "content: are you? Fine", "chunk:How are you?", "chunk:Fine", "finish:15"}
+	if strings.Join(events, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected events %q, but got %q", expected, events)
+	}
+}
+
+func TestSentenceChunker_WrapError(t *testing.T) {
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"content":"Hello. Wor"},"finish_reason":null}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"error":{"message":"Server overloaded","type":"server_error"}}`+"\n\n")
+	})
+
+	var events []string
+	chunker := deepseek_api.NewSentenceChunker(func(chunk string) { events = append(events, "chunk:"+chunk) })
+	handler := chunker.Wrap(&deepseek_api.DeepSeekStreamHandler{
+		OnError: func(err error) { events = append(events, "error:"+err.Error()) },
+	})
+
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+	client.StreamChat(context.Background(), request, handler)
+
+	expected := []string{"chunk:Hello.", "chunk:Wor", "error:deepseek error: Server overloaded"}
+	if strings.Join(events, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected events %q, but got %q", expected, events)
+	}
+}
@@ -1,7 +1,12 @@
 package deepseek_api_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -101,3 +106,81 @@ func TestDeepSeekClient_Validation(t *testing.T) {
 		}
 	})
 }
+
+func newTestDeepSeekClient(t *testing.T, handler http.HandlerFunc) *deepseek_api.DeepSeekClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return deepseek_api.NewDeepSeekClient(
+		deepseek_api.WithDeepSeekClientCommunication("http", strings.TrimPrefix(server.URL, "http://")),
+		deepseek_api.WithDeepSeekClientApi("test_api_key"),
+		deepseek_api.WithDeepSeekClientHttpClient(server.Client()),
+	)
+}
+
+func writeTestChatResponse(w http.ResponseWriter, content string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"id":     "chatcmpl-test",
+		"object": deepseek_api.OBJECT_CHAT_COMPLETION,
+		"model":  deepseek_api.MODEL_DEEPSEEK_CHAT,
+		"choices": []map[string]any{
+			{
+				"index":         0,
+				"finish_reason": "stop",
+				"message":       map[string]any{"role": deepseek_api.ROLE_ASSISTANT, "content": content},
+			},
+		},
+		"usage": map[string]any{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+	})
+}
+
+func TestDeepSeekClient_ChatPrefix(t *testing.T) {
+	var path string
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		writeTestChatResponse(w, "print('hello')\n```")
+	})
+
+	messages := []deepseek_api.DeepSeekMessage{
+		&deepseek_api.UserMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_USER, Content: "Write hello world in python."}},
+		&deepseek_api.AssistantMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_ASSISTANT, Content: "```python\n"}, Prefix: true},
+	}
+
+	chat_response, err := client.ChatPrefix(deepseek_api.NewDeepSeekChatRequest(messages, deepseek_api.MODEL_DEEPSEEK_CHAT))
+	if err != nil {
+		t.Fatalf("ChatPrefix error: %v", err)
+	}
+	if path != deepseek_api.DEFAULT_BETA_CHAT_PATH {
+		t.Errorf("Expected request to %s, but got %s", deepseek_api.DEFAULT_BETA_CHAT_PATH, path)
+	}
+	if content := chat_response.Choices[0].Message.Content; content != "```python\nprint('hello')\n```" {
+		t.Errorf("Unexpected content: %q", content)
+	}
+
+	_, err = client.ChatPrefix(deepseek_api.NewDeepSeekChatRequest(messages[:1], deepseek_api.MODEL_DEEPSEEK_CHAT))
+	if err == nil {
+		t.Error("Expected error without prefix message, but got none")
+	}
+
+	_, err = client.ChatPrefix(nil)
+	if err == nil || err.Error() != "chat request cannot be nil" {
+		t.Errorf("Expected nil request error, but got %v", err)
+	}
+}
+
+func TestDeepSeekClient_ChatPrefixContext(t *testing.T) {
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeTestChatResponse(w, "print('hello')")
+	})
+
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Write hello world in python.").Prefix("```python\n").Build()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.ChatPrefixContext(ctx, request)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, but got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package deepseek_api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestDeepSeekClient_ChatContinuation(t *testing.T) {
+	tests := []struct {
+		name         string
+		continuation int64
+		prefix       string
+		segments     []string
+		content      string
+		finish       string
+		requests     []string
+		usage        int64
+	}{
+		{
+			name:     "disabled",
+			segments: []string{"Once upon", " a time", "."},
+			content:  "Once upon",
+			finish:   deepseek_api.FINISH_REASON_LENGTH,
+			requests: []string{"/chat/completions:100:"},
+			usage:    10,
+		},
+		{
+			name:         "continues until stop",
+			continuation: 1000,
+			segments:     []string{"Once upon", " a time", "."},
+			content:      "Once upon a time.",
+			finish:       deepseek_api.FINISH_REASON_STOP,
+			requests:     []string{"/chat/completions:100:", "/beta/chat/completions:100:Once upon", "/beta/chat/completions:100:Once upon a time"},
+			usage:        30,
+		},
+		{
+			name:         "token cap",
+			continuation: 20,
+			segments:     []string{"Once upon", " a time", "."},
+			content:      "Once upon a time",
+			finish:       deepseek_api.FINISH_REASON_LENGTH,
+			requests:     []string{"/chat/completions:100:", "/beta/chat/completions:10:Once upon"},
+			usage:        20,
+		},
+		{
+			name:         "extends existing prefix",
+			continuation: 1000,
+			prefix:       "Once",
+			segments:     []string{" upon", " a time."},
+			content:      " upon a time.",
+			finish:       deepseek_api.FINISH_REASON_STOP,
+			requests:     []string{"/chat/completions:100:Once", "/beta/chat/completions:100:Once upon"},
+			usage:        20,
+		},
+		{
+			name:         "not truncated",
+			continuation: 1000,
+			segments:     []string{"Done."},
+			content:      "Done.",
+			finish:       deepseek_api.FINISH_REASON_STOP,
+			requests:     []string{"/chat/completions:100:"},
+			usage:        10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+				request := struct {
+					MaxTokens int64 `json:"max_tokens"`
+					Messages  []struct {
+						Content string `json:"content"`
+						Prefix  bool   `json:"prefix"`
+					} `json:"messages"`
+				}{}
+				json.NewDecoder(r.Body).Decode(&request)
+
+				prefix := ""
+				if last := request.Messages[len(request.Messages)-1]; last.Prefix {
+					prefix = last.Content
+				}
+				requests = append(requests, fmt.Sprintf("%s:%d:%s", r.URL.Path, request.MaxTokens, prefix))
+
+				segment := len(requests) - 1
+				finish_reason := deepseek_api.FINISH_REASON_LENGTH
+				if segment == len(tt.segments)-1 {
+					finish_reason = deepseek_api.FINISH_REASON_STOP
+				}
+
+				completion_tokens := int64(10)
+				if request.MaxTokens < completion_tokens {
+					completion_tokens = request.MaxTokens
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]any{
+					"id":      "chatcmpl-test",
+					"object":  deepseek_api.OBJECT_CHAT_COMPLETION,
+					"model":   deepseek_api.MODEL_DEEPSEEK_CHAT,
+					"choices": []map[string]any{{"index": 0, "finish_reason": finish_reason, "message": map[string]any{"role": deepseek_api.ROLE_ASSISTANT, "content": tt.segments[segment]}}},
+					"usage":   map[string]any{"prompt_tokens": 5, "completion_tokens": completion_tokens, "total_tokens": 5 + completion_tokens},
+				})
+			}).SetContinuation(tt.continuation)
+
+			builder := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Tell me a story").MaxTokens(100)
+			if tt.prefix != "" {
+				builder.Prefix(tt.prefix)
+			}
+			request, _ := builder.Build()
+			messages := len(request.Messages)
+
+			response, err := client.Chat(request)
+			if err != nil {
+				t.Fatalf("Expected no error, but got %v", err)
+			}
+
+			if response.Choices[0].Message.Content != tt.content {
+				t.Errorf("Expected content %q, but got %q", tt.content, response.Choices[0].Message.Content)
+			}
+			if response.Choices[0].FinishReason != tt.finish {
+				t.Errorf("Expected finish reason %s, but got %s", tt.finish, response.Choices[0].FinishReason)
+			}
+			if response.Usage.CompletionTokens != tt.usage {
+				t.Errorf("Expected %d completion tokens, but got %d", tt.usage, response.Usage.CompletionTokens)
+			}
+			if strings.Join(requests, "|") != strings.Join(tt.requests, "|") {
+				t.Errorf("Expected requests %q, but got %q", tt.requests, requests)
+			}
+			if len(request.Messages) != messages {
+				t.Errorf("Expected the original request to be left untouched, but got %d messages", len(request.Messages))
+			}
+		})
+	}
+}
+
+func TestDeepSeekClient_ChatContinuationError(t *testing.T) {
+	var calls int
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls > 1 {
+			http.Error(w, `{"error": {"message": "Server overloaded"}}`, http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":      "chatcmpl-test",
+			"object":  deepseek_api.OBJECT_CHAT_COMPLETION,
+			"model":   deepseek_api.MODEL_DEEPSEEK_CHAT,
+			"choices": []map[string]any{{"index": 0, "finish_reason": deepseek_api.FINISH_REASON_LENGTH, "message": map[string]any{"role": deepseek_api.ROLE_ASSISTANT, "content": "Once upon"}}},
+			"usage":   map[string]any{"prompt_tokens": 5, "completion_tokens": 10, "total_tokens": 15},
+		})
+	}).SetContinuation(1000)
+
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Tell me a story").MaxTokens(100).Build()
+	response, err := client.Chat(request)
+	if err == nil {
+		t.Fatal("Expected failed continuation to return an error")
+	}
+
+	if response == nil {
+		t.Fatal("Expected the partial response to be returned with the error")
+	}
+	if response.Choices[0].Message.Content != "Once upon" || response.Choices[0].FinishReason != deepseek_api.FINISH_REASON_LENGTH {
+		t.Errorf("Expected the first segment to be kept, but got %+v", response.Choices[0])
+	}
+	if response.Usage.CompletionTokens != 10 {
+		t.Errorf("Expected %d completion tokens, but got %d", 10, response.Usage.CompletionTokens)
+	}
+}
@@ -0,0 +1,98 @@
+package deepseek_api_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func newTestToolCall(id string) deepseek_api.ToolCall {
+	tool_call := deepseek_api.ToolCall{Id: id, Type: deepseek_api.TOOL_TYPE_FUNCTION}
+	tool_call.Function.Name = "get_weather"
+	return tool_call
+}
+
+func TestValidateConversation(t *testing.T) {
+	user := &deepseek_api.UserMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_USER, Content: "Weather?"}}
+	assistant := &deepseek_api.AssistantMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_ASSISTANT, Content: "Sunny."}}
+	prefix := &deepseek_api.AssistantMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_ASSISTANT, Content: "It is"}, Prefix: true}
+	calls := &deepseek_api.AssistantMessage{
+		BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_ASSISTANT},
+		ToolCalls:    []deepseek_api.ToolCall{newTestToolCall("call_1"), newTestToolCall("call_2")},
+	}
+	tool := func(id string) *deepseek_api.ToolMessage {
+		return &deepseek_api.ToolMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_TOOL, Content: "Sunny"}, ToolCallId: id}
+	}
+
+	tests := []struct {
+		name     string
+		model    string
+		messages []deepseek_api.DeepSeekMessage
+		expected []string
+	}{
+		{
+			name:     "Valid Tool Round",
+			model:    deepseek_api.MODEL_DEEPSEEK_CHAT,
+			messages: []deepseek_api.DeepSeekMessage{user, calls, tool("call_1"), tool("call_2"), assistant, user},
+			expected: nil,
+		},
+		{
+			name:     "Valid Prefix",
+			model:    deepseek_api.MODEL_DEEPSEEK_CHAT,
+			messages: []deepseek_api.DeepSeekMessage{user, prefix},
+			expected: nil,
+		},
+		{
+			name:     "Tool Without Call",
+			model:    deepseek_api.MODEL_DEEPSEEK_CHAT,
+			messages: []deepseek_api.DeepSeekMessage{user, tool("call_1")},
+			expected: []string{"messages[1].role"},
+		},
+		{
+			name:     "Mismatched And Missing Tool Results",
+			model:    deepseek_api.MODEL_DEEPSEEK_CHAT,
+			messages: []deepseek_api.DeepSeekMessage{user, calls, tool("call_1"), tool("call_1"), tool("call_3"), user},
+			expected: []string{"messages[3].tool_call_id", "messages[4].tool_call_id", "messages[1].tool_calls"},
+		},
+		{
+			name:     "Duplicated Tool Call Ids",
+			model:    deepseek_api.MODEL_DEEPSEEK_CHAT,
+			messages: []deepseek_api.DeepSeekMessage{user, calls, tool("call_1"), tool("call_2"), calls, tool("call_1"), tool("call_2"), user},
+			expected: []string{"messages[4].tool_calls[0].id", "messages[4].tool_calls[1].id", "messages[5].tool_call_id", "messages[6].tool_call_id"},
+		},
+		{
+			name:     "Reasoner Consecutive Assistant",
+			model:    deepseek_api.MODEL_DEEPSEEK_REASONER,
+			messages: []deepseek_api.DeepSeekMessage{user, assistant, assistant, user},
+			expected: []string{"messages[2].role"},
+		},
+		{
+			name:     "Trailing Assistant And Early Prefix",
+			model:    deepseek_api.MODEL_DEEPSEEK_CHAT,
+			messages: []deepseek_api.DeepSeekMessage{user, prefix, user, assistant},
+			expected: []string{"messages[1].prefix", "messages[3].prefix"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := deepseek_api.ValidateConversation(test.messages, test.model)
+			if test.expected == nil {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			var validation_err *deepseek_api.ValidationError
+			if !errors.As(err, &validation_err) {
+				t.Fatalf("Expected ValidationError, but got %v", err)
+			}
+			if fields := validation_err.Fields(); !reflect.DeepEqual(fields, test.expected) {
+				t.Errorf("Expected fields %v, but got %v (%v)", test.expected, fields, err)
+			}
+		})
+	}
+}
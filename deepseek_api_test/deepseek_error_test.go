@@ -0,0 +1,96 @@
+package deepseek_api_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestValidationError_Fields(t *testing.T) {
+	messages := []deepseek_api.DeepSeekMessage{
+		&deepseek_api.UserMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_USER, Content: "Hello"}},
+		&deepseek_api.UserMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_USER}},
+		&deepseek_api.ToolMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_TOOL, Content: "Sunny"}},
+	}
+
+	request := deepseek_api.NewDeepSeekChatRequest(messages, deepseek_api.MODEL_DEEPSEEK_CHAT)
+	request.Temperature = 5
+
+	err := request.DeepSeekRequest()
+	if !errors.Is(err, deepseek_api.ErrValidation) {
+		t.Fatalf("Expected ErrValidation, but got %v", err)
+	}
+
+	var validation_err *deepseek_api.ValidationError
+	if !errors.As(err, &validation_err) {
+		t.Fatalf("Expected ValidationError, but got %T", err)
+	}
+
+	expected := []string{"messages[1].content", "messages[2].tool_call_id", "messages[2].role", "temperature"}
+	if fields := validation_err.Fields(); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected fields %v, but got %v", expected, fields)
+	}
+
+	tool_call_err := validation_err.Errors[1]
+	if tool_call_err.Constraint != deepseek_api.CONSTRAINT_REQUIRED || tool_call_err.Error() != "messages[2].tool_call_id cannot be empty" {
+		t.Errorf("Unexpected field error: %+v", tool_call_err)
+	}
+	if validation_err.Errors[3].Value != float64(5) {
+		t.Errorf("Expected offending value 5, but got %v", validation_err.Errors[3].Value)
+	}
+
+	var field_err *deepseek_api.FieldError
+	if !errors.As(err, &field_err) || field_err.Field != "messages[1].content" {
+		t.Errorf("Expected first FieldError, but got %v", field_err)
+	}
+	if !errors.Is(err, tool_call_err) {
+		t.Error("Expected errors.Is to match a contained FieldError")
+	}
+}
+
+func TestDeepSeekStatusError_Error(t *testing.T) {
+	tests := []struct {
+		err      *deepseek_api.DeepSeekStatusError
+		expected string
+	}{
+		{&deepseek_api.DeepSeekStatusError{StatusCode: 429}, "HTTP request failed with status code 429"},
+		{&deepseek_api.DeepSeekStatusError{StatusCode: 429, Message: "Rate limit reached"}, "HTTP request failed with status code 429: Rate limit reached"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if tt.err.Error() != tt.expected {
+				t.Errorf("Expected %q, but got %q", tt.expected, tt.err.Error())
+			}
+		})
+	}
+}
+
+func TestValidationError_Unique(t *testing.T) {
+	messages := []deepseek_api.DeepSeekMessage{
+		&deepseek_api.UserMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_USER, Content: "Hello"}},
+		&deepseek_api.AssistantMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_ASSISTANT, Content: "Hi"}},
+		&deepseek_api.ToolMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_TOOL, Content: "Sunny"}},
+	}
+
+	err := deepseek_api.NewDeepSeekChatRequest(messages, deepseek_api.MODEL_DEEPSEEK_CHAT).DeepSeekRequest()
+
+	var validation_err *deepseek_api.ValidationError
+	if !errors.As(err, &validation_err) {
+		t.Fatalf("Expected ValidationError, but got %v", err)
+	}
+
+	seen := map[string]bool{}
+	for _, field_err := range validation_err.Errors {
+		key := field_err.Field + " " + field_err.Constraint
+		if seen[key] {
+			t.Errorf("Duplicate field error %s in %v", key, err)
+		}
+		seen[key] = true
+	}
+	if !seen["messages[2].tool_call_id "+deepseek_api.CONSTRAINT_REQUIRED] {
+		t.Errorf("Expected missing tool_call_id error, but got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package deepseek_api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func newTestEndpoint(t *testing.T, name string, handler http.HandlerFunc) deepseek_api.DeepSeekEndpoint {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return deepseek_api.DeepSeekEndpoint{
+		Name:       name,
+		Protocol:   "http",
+		Host:       strings.TrimPrefix(server.URL, "http://"),
+		ApiKey:     name + "_api_key",
+		HttpClient: server.Client(),
+	}
+}
+
+func TestDeepSeekFailoverClient_Chat(t *testing.T) {
+	primary_calls := 0
+	primary := newTestEndpoint(t, "primary", func(w http.ResponseWriter, r *http.Request) {
+		primary_calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	var path, model string
+	secondary := newTestEndpoint(t, "secondary", func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body := map[string]any{}
+		json.NewDecoder(r.Body).Decode(&body)
+		model, _ = body["model"].(string)
+		writeTestChatResponse(w, "Hello")
+	})
+	secondary.BasePath = "/v1/"
+	secondary.Models = map[string]string{deepseek_api.MODEL_DEEPSEEK_CHAT: "deepseek-ai/DeepSeek-V3"}
+
+	client := deepseek_api.NewDeepSeekFailoverClient(
+		[]deepseek_api.DeepSeekEndpoint{primary, secondary},
+		deepseek_api.WithDeepSeekFailoverBreaker(
+			deepseek_api.WithCircuitBreakerFailureThreshold(2),
+			deepseek_api.WithCircuitBreakerOpenTimeout(time.Minute),
+		),
+	)
+
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Chat(request)
+		if err != nil {
+			t.Fatalf("Chat error: %v", err)
+		}
+		if resp.Choices[0].Message.Content != "Hello" {
+			t.Errorf("Expected content %q, but got %q", "Hello", resp.Choices[0].Message.Content)
+		}
+	}
+
+	if primary_calls != 2 {
+		t.Errorf("Expected primary to be skipped once its breaker opens, but got %d calls", primary_calls)
+	}
+	if path != "/v1"+deepseek_api.DEFAULT_CHAT_PATH {
+		t.Errorf("Expected path %q, but got %q", "/v1"+deepseek_api.DEFAULT_CHAT_PATH, path)
+	}
+	if model != "deepseek-ai/DeepSeek-V3" {
+		t.Errorf("Expected mapped model, but got %q", model)
+	}
+	if request.Model != deepseek_api.MODEL_DEEPSEEK_CHAT {
+		t.Errorf("Expected request model to be unchanged, but got %q", request.Model)
+	}
+
+	status := client.Status()
+	if status[0].State != deepseek_api.CIRCUIT_OPEN || status[1].State != deepseek_api.CIRCUIT_CLOSED {
+		t.Errorf("Unexpected endpoint status: %+v", status)
+	}
+}
+
+func TestDeepSeekFailoverClient_ClientErrorDoesNotFailOver(t *testing.T) {
+	primary := newTestEndpoint(t, "primary", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"message": "Invalid request", "type": "invalid_request_error"}}`))
+	})
+
+	secondary_calls := 0
+	secondary := newTestEndpoint(t, "secondary", func(w http.ResponseWriter, r *http.Request) {
+		secondary_calls++
+		writeTestChatResponse(w, "Hello")
+	})
+
+	client := deepseek_api.NewDeepSeekFailoverClient([]deepseek_api.DeepSeekEndpoint{primary, secondary})
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+
+	_, err := client.Chat(request)
+
+	var status_error *deepseek_api.DeepSeekStatusError
+	if !errors.As(err, &status_error) || status_error.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected a 400 status error, but got %v", err)
+	}
+	if secondary_calls != 0 {
+		t.Errorf("Expected no failover on client error, but secondary was called %d times", secondary_calls)
+	}
+}
+
+func TestDeepSeekFailoverClient_ConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	host := strings.TrimPrefix(server.URL, "http://")
+	server.Close()
+
+	primary := deepseek_api.DeepSeekEndpoint{Name: "primary", Protocol: "http", Host: host, ApiKey: "primary_api_key"}
+	secondary := newTestEndpoint(t, "secondary", func(w http.ResponseWriter, r *http.Request) {
+		writeTestChatResponse(w, "Hello")
+	})
+
+	client := deepseek_api.NewDeepSeekFailoverClient([]deepseek_api.DeepSeekEndpoint{primary, secondary})
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+
+	if _, err := client.Chat(request); err != nil {
+		t.Fatalf("Chat error: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package deepseek_api_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestDeepSeekClient_Deduplication(t *testing.T) {
+	tests := []struct {
+		name        string
+		status_code int
+		expected    error
+	}{
+		{
+			name:        "shared response",
+			status_code: http.StatusOK,
+		},
+		{
+			name:        "shared error",
+			status_code: http.StatusInternalServerError,
+			expected:    &deepseek_api.DeepSeekStatusError{StatusCode: http.StatusInternalServerError},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			release := make(chan struct{})
+			client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				if tt.status_code != http.StatusOK {
+					w.WriteHeader(tt.status_code)
+					return
+				}
+				writeTestChatResponse(w, "Hello")
+			})
+			client.SetDeduplication(true)
+
+			request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+
+			errs := make([]error, 5)
+			var wg sync.WaitGroup
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, errs[i] = client.Chat(request)
+				}(i)
+			}
+
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if calls != 1 {
+				t.Errorf("Expected 1 call, but got %d", calls)
+			}
+			for i, err := range errs {
+				var status_error *deepseek_api.DeepSeekStatusError
+				if tt.expected == nil && err != nil {
+					t.Errorf("Caller %d: unexpected error %v", i, err)
+				} else if tt.expected != nil && (!errors.As(err, &status_error) || status_error.StatusCode != tt.status_code) {
+					t.Errorf("Caller %d: expected status error, but got %v", i, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDeepSeekClient_DeduplicationCancellation(t *testing.T) {
+	cancelled := make(chan struct{})
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+		close(cancelled)
+	})
+	client.SetDeduplication(true)
+
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+
+	first_ctx, first_cancel := context.WithCancel(context.Background())
+	second_ctx, second_cancel := context.WithCancel(context.Background())
+
+	first_err := make(chan error, 1)
+	second_err := make(chan error, 1)
+	go func() {
+		_, err := client.ChatContext(first_ctx, request)
+		first_err <- err
+	}()
+	go func() {
+		_, err := client.ChatContext(second_ctx, request)
+		second_err <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	first_cancel()
+	if err := <-first_err; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected first caller to be cancelled, but got %v", err)
+	}
+
+	select {
+	case <-cancelled:
+		t.Fatal("Expected shared call to continue while a waiter remains")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	second_cancel()
+	if err := <-second_err; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected second caller to be cancelled, but got %v", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("Expected shared call to be cancelled once every waiter left")
+	}
+}
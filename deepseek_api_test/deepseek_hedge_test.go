@@ -0,0 +1,90 @@
+package deepseek_api_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestDeepSeekClient_HedgedChat(t *testing.T) {
+	tests := []struct {
+		name          string
+		primary_delay time.Duration
+		deduplicate   bool
+		expected_won  bool
+		expected      deepseek_api.HedgeStats
+	}{
+		{
+			name:          "primary completes before delay",
+			primary_delay: 0,
+			expected_won:  false,
+			expected:      deepseek_api.HedgeStats{Requests: 1},
+		},
+		{
+			name:          "hedge wins and primary is cancelled",
+			primary_delay: time.Second,
+			expected_won:  true,
+			expected:      deepseek_api.HedgeStats{Requests: 1, Hedged: 1, HedgeWins: 1},
+		},
+		{
+			name:          "hedge bypasses deduplication",
+			primary_delay: time.Second,
+			deduplicate:   true,
+			expected_won:  true,
+			expected:      deepseek_api.HedgeStats{Requests: 1, Hedged: 1, HedgeWins: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			cancelled := make(chan struct{}, 1)
+			client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+				if atomic.AddInt32(&calls, 1) == 1 && tt.primary_delay > 0 {
+					select {
+					case <-time.After(tt.primary_delay):
+					case <-r.Context().Done():
+						cancelled <- struct{}{}
+						return
+					}
+				}
+				writeTestChatResponse(w, "Hello")
+			})
+			client.SetDeduplication(tt.deduplicate)
+
+			request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+
+			resp, won, err := client.HedgedChat(context.Background(), request, 50*time.Millisecond)
+			if err != nil {
+				t.Fatalf("HedgedChat error: %v", err)
+			}
+			if resp.Choices[0].Message.Content != "Hello" {
+				t.Errorf("Expected content %q, but got %q", "Hello", resp.Choices[0].Message.Content)
+			}
+			if won != tt.expected_won {
+				t.Errorf("Expected hedge won %v, but got %v", tt.expected_won, won)
+			}
+			if stats := client.HedgeStats(); stats != tt.expected {
+				t.Errorf("Expected stats %+v, but got %+v", tt.expected, stats)
+			}
+
+			if tt.primary_delay > 0 && atomic.LoadInt32(&calls) != 2 {
+				t.Errorf("Expected hedge to reach upstream, but got %d calls", atomic.LoadInt32(&calls))
+			}
+
+			if tt.primary_delay > 0 {
+				select {
+				case <-cancelled:
+				case <-time.After(tt.primary_delay):
+					t.Error("Expected losing request to be cancelled")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package deepseek_api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"Plain", `{"a":1}`, `{"a":1}`},
+		{"Code Fence", "```json\n{\"a\":1}\n```", `{"a":1}`},
+		{"Trailing Prose", `Here you go: {"a":[1,2]} Hope this helps!`, `{"a":[1,2]}`},
+		{"Trailing Comma", `{"a":[1,2,],}`, `{"a":[1,2]}`},
+		{"Truncated", `{"a":{"b":"c`, `{"a":{"b":"c"}}`},
+		{"Braces In String", `{"a":"}{"} tail`, `{"a":"}{"}`},
+		{"Bracketed Prose", `Sure [see below]: {"a": 1}`, `{"a": 1}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			raw, err := deepseek_api.ExtractJSON(test.content)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(raw) != test.expected {
+				t.Errorf("Expected %s, but got %s", test.expected, raw)
+			}
+		})
+	}
+
+	if _, err := deepseek_api.ExtractJSON("no json here"); err == nil {
+		t.Error("Expected error for content without json, but got none")
+	}
+}
+
+func TestChatJSON(t *testing.T) {
+	type answer struct {
+		City  string `json:"city"`
+		Score int    `json:"score"`
+	}
+
+	replies := []string{"Sorry, I cannot do that.", "```json\n{\"city\": \"Hangzhou\", \"score\": 9,}\n```"}
+	var requests []map[string]any
+
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+		writeTestChatResponse(w, replies[len(requests)-1])
+	})
+
+	messages := []deepseek_api.DeepSeekMessage{&deepseek_api.UserMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_USER, Content: "Reply in json."}}}
+	request := deepseek_api.NewDeepSeekChatRequest(messages, deepseek_api.MODEL_DEEPSEEK_CHAT)
+
+	value, _, err := deepseek_api.ChatJSON[answer](context.Background(), client, request, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if value.City != "Hangzhou" || value.Score != 9 {
+		t.Errorf("Unexpected value: %+v", value)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 requests, but got %d", len(requests))
+	}
+	if format := requests[0]["response_format"].(map[string]any)["type"]; format != deepseek_api.RESPONSE_FORMAT_JSON_OBJECT {
+		t.Errorf("Expected json_object response format, but got %v", format)
+	}
+	if retry_messages := requests[1]["messages"].([]any); len(retry_messages) != 3 {
+		t.Errorf("Expected retry to carry 3 messages, but got %d", len(retry_messages))
+	}
+	if len(request.Messages) != 1 {
+		t.Errorf("Caller messages were modified: %d", len(request.Messages))
+	}
+
+	replies = []string{"nope", "still nope"}
+	requests = nil
+	_, _, err = deepseek_api.ChatJSON[answer](context.Background(), client, request, 2)
+	var decode_err *deepseek_api.JSONDecodeError
+	if !errors.As(err, &decode_err) || decode_err.Attempts != 2 {
+		t.Errorf("Expected JSONDecodeError after 2 attempts, but got %v", err)
+	}
+}
+
+func TestChatJSON_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	requests := 0
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		cancel()
+		writeTestChatResponse(w, "Sorry, I cannot do that.")
+	})
+
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Reply in json.").Build()
+
+	_, _, err := deepseek_api.ChatJSON[map[string]any](ctx, client, request, 3)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, but got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected repair loop to stop after cancellation, but got %d requests", requests)
+	}
+}
@@ -0,0 +1,159 @@
+package deepseek_api_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func newTestKeyPoolClient(t *testing.T, key_pool *deepseek_api.DeepSeekKeyPool, handler http.HandlerFunc) *deepseek_api.DeepSeekClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return deepseek_api.NewDeepSeekClient(
+		deepseek_api.WithDeepSeekClientCommunication("http", strings.TrimPrefix(server.URL, "http://")),
+		deepseek_api.WithDeepSeekClientKeyPool(key_pool),
+		deepseek_api.WithDeepSeekClientHttpClient(server.Client()),
+	)
+}
+
+func TestDeepSeekKeyPool_Rotation(t *testing.T) {
+	key_pool := deepseek_api.NewDeepSeekKeyPool(
+		[]string{"sk-limited-0001", "sk-revoked-0002", "sk-healthy-0003"},
+		deepseek_api.WithDeepSeekKeyPoolStrategy(deepseek_api.LeastInFlightStrategy{}),
+	)
+
+	var used []string
+	client := newTestKeyPoolClient(t, key_pool, func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		used = append(used, key)
+		switch key {
+		case "sk-limited-0001":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "sk-revoked-0002":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": {"message": "Authentication Fails", "type": "authentication_error"}}`))
+		default:
+			writeTestChatResponse(w, "Hello")
+		}
+	})
+
+	request := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello")
+	chat_request, _ := request.Build()
+
+	if _, err := client.Chat(chat_request); err != nil {
+		t.Fatalf("Chat error: %v", err)
+	}
+	if len(used) != 3 {
+		t.Fatalf("Expected 3 attempts, but got %v", used)
+	}
+
+	stats := key_pool.Stats()
+	if stats[0].RateLimited != 1 || stats[0].CoolingUntil.IsZero() {
+		t.Errorf("Expected first key to be cooling, but got %+v", stats[0])
+	}
+	if stats[1].Unauthorized != 1 || stats[1].QuarantinedUntil.IsZero() {
+		t.Errorf("Expected second key to be quarantined, but got %+v", stats[1])
+	}
+	if stats[2].Requests != 1 || stats[2].InFlight != 0 {
+		t.Errorf("Unexpected healthy key stats: %+v", stats[2])
+	}
+	if stats[2].Key != "sk-...0003" {
+		t.Errorf("Expected masked key, but got %s", stats[2].Key)
+	}
+
+	used = nil
+	if _, err := client.Chat(chat_request); err != nil {
+		t.Fatalf("Chat error: %v", err)
+	}
+	if len(used) != 1 || used[0] != "sk-healthy-0003" {
+		t.Errorf("Expected only healthy key to be used, but got %v", used)
+	}
+}
+
+func TestDeepSeekKeyPool_AllKeysFail(t *testing.T) {
+	key_pool := deepseek_api.NewDeepSeekKeyPool([]string{"sk-limited-0001", "sk-limited-0002"})
+	client := newTestKeyPoolClient(t, key_pool, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	chat_request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+
+	_, err := client.Chat(chat_request)
+	var status_err *deepseek_api.DeepSeekStatusError
+	if !errors.As(err, &status_err) || status_err.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 status error, but got %v", err)
+	}
+
+	_, err = client.Chat(chat_request)
+	if !errors.Is(err, deepseek_api.ErrNoAvailableKey) {
+		t.Errorf("Expected ErrNoAvailableKey while keys cool down, but got %v", err)
+	}
+}
+
+func TestDeepSeekKeyPool_RefreshBalances(t *testing.T) {
+	key_pool := deepseek_api.NewDeepSeekKeyPool(
+		[]string{"sk-poor-00000001", "sk-rich-00000002"},
+		deepseek_api.WithDeepSeekKeyPoolStrategy(deepseek_api.MostBalanceStrategy{}),
+	)
+
+	var used []string
+	client := newTestKeyPoolClient(t, key_pool, func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if r.URL.Path == deepseek_api.DEFAULT_BALANCE_PATH {
+			balance := "1.00"
+			if key == "sk-rich-00000002" {
+				balance = "99.50"
+			}
+			w.Write([]byte(`{"is_available": true, "balance_infos": [{"currency": "CNY", "total_balance": "` + balance + `"}]}`))
+			return
+		}
+		used = append(used, key)
+		writeTestChatResponse(w, "Hello")
+	})
+
+	if err := key_pool.RefreshBalances(client); err != nil {
+		t.Fatalf("RefreshBalances error: %v", err)
+	}
+
+	stats := key_pool.Stats()
+	if stats[0].Balance != 1 || stats[1].Balance != 99.5 || stats[1].Currency != "CNY" {
+		t.Errorf("Unexpected balances: %+v", stats)
+	}
+
+	chat_request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+	if _, err := client.Chat(chat_request); err != nil {
+		t.Fatalf("Chat error: %v", err)
+	}
+	if len(used) != 1 || used[0] != "sk-rich-00000002" {
+		t.Errorf("Expected richest key to be used, but got %v", used)
+	}
+}
+
+func TestKeySelectionStrategy_Select(t *testing.T) {
+	candidates := []deepseek_api.KeyStats{{InFlight: 3, Balance: 5}, {InFlight: 1, Balance: 2}, {InFlight: 2, Balance: 9}}
+
+	tests := []struct {
+		name     string
+		strategy deepseek_api.KeySelectionStrategy
+		expected []int
+	}{
+		{"Round Robin", &deepseek_api.RoundRobinStrategy{}, []int{0, 1, 2, 0}},
+		{"Least In Flight", deepseek_api.LeastInFlightStrategy{}, []int{1, 1}},
+		{"Most Balance", deepseek_api.MostBalanceStrategy{}, []int{2, 2}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for i, expected := range test.expected {
+				if selected := test.strategy.Select(candidates); selected != expected {
+					t.Errorf("Selection %d: expected %d, but got %d", i, expected, selected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package deepseek_api_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestModelRegistry_Capability(t *testing.T) {
+	registry := deepseek_api.NewModelRegistry(deepseek_api.ModelCapability{Id: "tiny", MaxOutputTokens: 256, DefaultMaxTokens: 128})
+
+	if capability, ok := registry.Lookup("tiny"); !ok || capability.MaxOutputTokens != 256 {
+		t.Errorf("Unexpected capability: %+v", capability)
+	}
+
+	capability := registry.Capability("unknown")
+	if capability.Id != "unknown" || capability.MaxOutputTokens != deepseek_api.DEFAULT_MODEL_CAPABILITY.MaxOutputTokens {
+		t.Errorf("Expected default capability for unknown model, but got %+v", capability)
+	}
+
+	if max_tokens := deepseek_api.NewDeepSeekChatRequest(nil, deepseek_api.MODEL_DEEPSEEK_REASONER).MaxTokens; max_tokens != 32*1024 {
+		t.Errorf("Expected reasoner default max_tokens 32768, but got %d", max_tokens)
+	}
+}
+
+func TestDeepSeekChatRequest_ValidateWithRegistry(t *testing.T) {
+	messages := []deepseek_api.DeepSeekMessage{&deepseek_api.BasicMessage{Role: deepseek_api.ROLE_USER, Content: "Hello"}}
+
+	tests := []struct {
+		name     string
+		model    string
+		modify   func(request *deepseek_api.DeepSeekChatRequest)
+		expected error
+	}{
+		{
+			name:     "Reasoner Max Tokens",
+			model:    deepseek_api.MODEL_DEEPSEEK_REASONER,
+			modify:   func(request *deepseek_api.DeepSeekChatRequest) { request.MaxTokens = 60000 },
+			expected: nil,
+		},
+		{
+			name:     "Chat Max Tokens",
+			model:    deepseek_api.MODEL_DEEPSEEK_CHAT,
+			modify:   func(request *deepseek_api.DeepSeekChatRequest) { request.MaxTokens = 60000 },
+			expected: errors.New("max_tokens must be between 1 and 8192"),
+		},
+		{
+			name:     "Custom Model Max Tokens",
+			model:    "tiny",
+			modify:   func(request *deepseek_api.DeepSeekChatRequest) { request.MaxTokens = 512 },
+			expected: errors.New("max_tokens must be between 1 and 256; tools are not supported by tiny"),
+		},
+	}
+
+	registry := deepseek_api.NewModelRegistry(
+		deepseek_api.ModelCapability{Id: "tiny", MaxOutputTokens: 256, DefaultMaxTokens: 128},
+		deepseek_api.DefaultModelRegistry.Capability(deepseek_api.MODEL_DEEPSEEK_CHAT),
+		deepseek_api.DefaultModelRegistry.Capability(deepseek_api.MODEL_DEEPSEEK_REASONER),
+	)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := deepseek_api.NewDeepSeekChatRequest(messages, test.model)
+			request.Temperature = 1
+			if test.model == "tiny" {
+				tool := deepseek_api.Tool{Type: deepseek_api.TOOL_TYPE_FUNCTION}
+				tool.Function.Name = "get_weather"
+				request.Tools = []deepseek_api.Tool{tool}
+				request.ToolChoice = deepseek_api.TOOL_CHOICE_AUTO
+			}
+			test.modify(request)
+
+			err := request.ValidateWithRegistry(registry)
+			if err != nil && test.expected == nil {
+				t.Errorf("Unexpected error: %v", err)
+			} else if err == nil && test.expected != nil {
+				t.Errorf("Expected error: %v, but got none", test.expected)
+			} else if err != nil && test.expected != nil && err.Error() != test.expected.Error() {
+				t.Errorf("Expected error: %v, but got: %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestDeepSeekClient_RefreshModels(t *testing.T) {
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"object": "list", "data": [{"id": "deepseek-chat", "object": "model", "owned_by": "deepseek"}, {"id": "deepseek-future", "object": "model", "owned_by": "deepseek"}]}`))
+	})
+
+	registry := deepseek_api.NewModelRegistry(deepseek_api.ModelCapability{Id: deepseek_api.MODEL_DEEPSEEK_CHAT, MaxOutputTokens: 100})
+	client.SetModelRegistry(registry)
+
+	if err := client.RefreshModels(); err != nil {
+		t.Fatalf("RefreshModels error: %v", err)
+	}
+
+	if capability, _ := registry.Lookup(deepseek_api.MODEL_DEEPSEEK_CHAT); capability.MaxOutputTokens != 100 {
+		t.Errorf("Expected known model to keep its capability, but got %+v", capability)
+	}
+	if _, ok := registry.Lookup("deepseek-future"); !ok {
+		t.Error("Expected deepseek-future to be registered")
+	}
+	if models := registry.Models(); len(models) != 2 {
+		t.Errorf("Expected 2 models, but got %d", len(models))
+	}
+}
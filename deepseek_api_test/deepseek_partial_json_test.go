@@ -0,0 +1,107 @@
+package deepseek_api_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestPartialJSONParser_Write(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{``, `null`},
+		{"```json\n{", `{}`},
+		{`{"na`, `{}`},
+		{`{"name"`, `{}`},
+		{`{"name": `, `{}`},
+		{`{"name": "Ali`, `{"name":"Ali"}`},
+		{`{"name": "Ali\`, `{"name":"Ali"}`},
+		{`{"name": "Ali\u00`, `{"name":"Ali"}`},
+		{`{"name": "Alié"`, `{"name":"Alié"}`},
+		{`{"name": "Alice", "age": 3`, `{"name":"Alice"}`},
+		{`{"name": "Alice", "age": 30,`, `{"age":30,"name":"Alice"}`},
+		{`{"name": "Alice", "ok": tr`, `{"name":"Alice"}`},
+		{`{"name": "Alice", "tags": ["a", "b`, `{"name":"Alice","tags":["a","b"]}`},
+		{`{"items": [{"id": 1}, {"id"`, `{"items":[{"id":1},{}]}`},
+		{`{"text": "a, b: {c} [d]"`, `{"text":"a, b: {c} [d]"}`},
+		{`[1, 2, 3]`, `[1,2,3]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			parser := deepseek_api.NewPartialJSONParser()
+
+			var partial any
+			for _, r := range tt.input {
+				partial = parser.Write(string(r))
+			}
+
+			actual, _ := json.Marshal(partial)
+			if string(actual) != tt.expected {
+				t.Errorf("Expected partial %s, but got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPartialJSONParser_Final(t *testing.T) {
+	parser := deepseek_api.NewPartialJSONParser()
+	parser.Write(`{"name": "Alice",`)
+
+	value := struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}{}
+	if err := parser.Final(&value); err == nil {
+		t.Error("Expected incomplete json to fail strict decoding")
+	}
+
+	parser.Write(` "age": 30}` + "\n```")
+	if !parser.Complete() {
+		t.Fatal("Expected parser to be complete")
+	}
+	if err := parser.Final(&value); err != nil || value.Name != "Alice" || value.Age != 30 {
+		t.Errorf("Unexpected final value %+v, %v", value, err)
+	}
+}
+
+func TestToolCallAccumulator(t *testing.T) {
+	delta := func(index int64, id string, name string, arguments string) deepseek_api.ToolCallDelta {
+		tool_call_delta := deepseek_api.ToolCallDelta{Index: index, Id: id}
+		tool_call_delta.Function.Name = name
+		tool_call_delta.Function.Arguments = arguments
+		return tool_call_delta
+	}
+
+	accumulator := deepseek_api.NewToolCallAccumulator()
+	accumulator.Add([]deepseek_api.ToolCallDelta{delta(0, "call_1", "get_weather", "")})
+	accumulator.Add([]deepseek_api.ToolCallDelta{delta(0, "", "", `{"city": "Par`)})
+	accumulator.Add([]deepseek_api.ToolCallDelta{delta(1, "call_2", "get_time", "")})
+
+	calls := accumulator.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("Expected 2 calls, but got %d", len(calls))
+	}
+	if partial, _ := json.Marshal(calls[0].Partial); string(partial) != `{"city":"Par"}` {
+		t.Errorf("Expected partial arguments, but got %s", partial)
+	}
+
+	if _, err := accumulator.ToolCalls(); err == nil {
+		t.Error("Expected incomplete arguments to fail")
+	}
+
+	accumulator.Add([]deepseek_api.ToolCallDelta{delta(0, "", "", `is"}`)})
+	tool_calls, err := accumulator.ToolCalls()
+	if err != nil {
+		t.Fatalf("ToolCalls error: %v", err)
+	}
+	if tool_calls[0].Id != "call_1" || tool_calls[0].Function.Name != "get_weather" || tool_calls[0].Function.Arguments != `{"city": "Paris"}` {
+		t.Errorf("Unexpected first tool call: %+v", tool_calls[0])
+	}
+	if tool_calls[1].Function.Arguments != "{}" {
+		t.Errorf("Expected empty arguments to default to {}, but got %q", tool_calls[1].Function.Arguments)
+	}
+}
@@ -0,0 +1,253 @@
+package deepseek_api_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func newTestProxy(t *testing.T, options ...deepseek_api.DeepSeekProxyOptions) (*deepseek_api.DeepSeekProxy, *httptest.Server) {
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case deepseek_api.DEFAULT_MODELS_PATH:
+			w.Write([]byte(`{"object": "list", "data": [{"id": "deepseek-chat", "object": "model", "owned_by": "deepseek"}]}`))
+		default:
+			body := map[string]any{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if stream, _ := body["stream"].(bool); stream {
+				writeTestChatStream(w, "Hel", "lo")
+				return
+			}
+			writeTestChatResponse(w, "Hello")
+		}
+	})
+
+	proxy := deepseek_api.NewDeepSeekProxy(client, options...)
+	server := httptest.NewServer(proxy)
+	t.Cleanup(server.Close)
+
+	return proxy, server
+}
+
+func doTestProxyRequest(t *testing.T, server *httptest.Server, method string, path string, virtual_key string, body string) (*http.Response, string) {
+	req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if virtual_key != "" {
+		req.Header.Set("Authorization", "Bearer "+virtual_key)
+	}
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	resp_body, _ := io.ReadAll(resp.Body)
+	return resp, string(resp_body)
+}
+
+func TestDeepSeekProxy_ServeHTTP(t *testing.T) {
+	chat_body := `{"model": "deepseek-chat", "messages": [{"role": "user", "content": "Hello"}]}`
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		virtual_key string
+		body        string
+		status_code int
+		contains    string
+	}{
+		{"chat", http.MethodPost, deepseek_api.PROXY_CHAT_PATH, "vk-team-a", chat_body, http.StatusOK, `"content":"Hello"`},
+		{"models", http.MethodGet, deepseek_api.PROXY_MODELS_PATH, "vk-team-a", "", http.StatusOK, `"id":"deepseek-chat"`},
+		{"missing key", http.MethodPost, deepseek_api.PROXY_CHAT_PATH, "", chat_body, http.StatusUnauthorized, `"type":"authentication_error"`},
+		{"unknown key", http.MethodPost, deepseek_api.PROXY_CHAT_PATH, "vk-unknown", chat_body, http.StatusUnauthorized, `"type":"authentication_error"`},
+		{"invalid request", http.MethodPost, deepseek_api.PROXY_CHAT_PATH, "vk-team-a", `{"model": "deepseek-chat", "messages": []}`, http.StatusBadRequest, `"type":"invalid_request_error"`},
+		{"body too large", http.MethodPost, deepseek_api.PROXY_CHAT_PATH, "vk-team-a", `{"model": "deepseek-chat"` + strings.Repeat(" ", deepseek_api.DEFAULT_PROXY_MAX_BODY) + `}`, http.StatusRequestEntityTooLarge, `"type":"invalid_request_error"`},
+		{"wrong method", http.MethodGet, deepseek_api.PROXY_CHAT_PATH, "vk-team-a", "", http.StatusMethodNotAllowed, `"type":"api_error"`},
+		{"unknown path", http.MethodGet, "/v1/embeddings", "vk-team-a", "", http.StatusNotFound, `"type":"not_found_error"`},
+	}
+
+	_, server := newTestProxy(t, deepseek_api.WithDeepSeekProxyTenant(deepseek_api.DeepSeekProxyTenant{Name: "team-a", VirtualKey: "vk-team-a"}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doTestProxyRequest(t, server, tt.method, tt.path, tt.virtual_key, tt.body)
+			if resp.StatusCode != tt.status_code {
+				t.Errorf("Expected status %d, but got %d: %s", tt.status_code, resp.StatusCode, body)
+			}
+			if !strings.Contains(body, tt.contains) {
+				t.Errorf("Expected body to contain %s, but got %s", tt.contains, body)
+			}
+		})
+	}
+}
+
+func TestDeepSeekProxy_Stream(t *testing.T) {
+	var entries []deepseek_api.DeepSeekProxyLogEntry
+	proxy, server := newTestProxy(t,
+		deepseek_api.WithDeepSeekProxyTenant(deepseek_api.DeepSeekProxyTenant{Name: "team-a", VirtualKey: "vk-team-a"}),
+		deepseek_api.WithDeepSeekProxyLogger(func(entry deepseek_api.DeepSeekProxyLogEntry) {
+			entries = append(entries, entry)
+		}),
+	)
+
+	resp, body := doTestProxyRequest(t, server, http.MethodPost, deepseek_api.PROXY_CHAT_PATH, "vk-team-a",
+		`{"model": "deepseek-chat", "stream": true, "messages": [{"role": "user", "content": "Hello"}]}`)
+
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Expected event stream, but got %s", resp.Header.Get("Content-Type"))
+	}
+	if strings.Count(body, "data: ") != 3 || !strings.HasSuffix(body, "data: [DONE]\n\n") {
+		t.Errorf("Expected two content events and done, but got %q", body)
+	}
+	if strings.Contains(body, `"usage"`) {
+		t.Errorf("Expected usage chunk to be withheld when not requested, but got %q", body)
+	}
+
+	if usage := proxy.TenantUsage()["team-a"]; usage.Requests != 1 || usage.Usage.TotalTokens != 15 {
+		t.Errorf("Expected streamed usage to be recorded, but got %+v", usage)
+	}
+	if len(entries) != 1 || !entries[0].Stream || entries[0].Tenant != "team-a" || entries[0].Usage.TotalTokens != 15 {
+		t.Errorf("Unexpected log entries: %+v", entries)
+	}
+}
+
+func TestDeepSeekProxy_Quota(t *testing.T) {
+	_, server := newTestProxy(t, deepseek_api.WithDeepSeekProxyTenant(deepseek_api.DeepSeekProxyTenant{Name: "team-a", VirtualKey: "vk-team-a", TokenQuota: 20}))
+
+	chat_body := `{"model": "deepseek-chat", "messages": [{"role": "user", "content": "Hello"}]}`
+	expected := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	for i, status_code := range expected {
+		resp, body := doTestProxyRequest(t, server, http.MethodPost, deepseek_api.PROXY_CHAT_PATH, "vk-team-a", chat_body)
+		if resp.StatusCode != status_code {
+			t.Errorf("Request %d: expected status %d, but got %d: %s", i, status_code, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestDeepSeekProxy_ToolChoice(t *testing.T) {
+	tools := `"tools": [{"type": "function", "function": {"name": "get_weather", "parameters": {"type": "object", "properties": {}}}}]`
+
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"tools only", `{"model": "deepseek-chat", ` + tools + `, "messages": [{"role": "user", "content": "Hello"}]}`, deepseek_api.TOOL_CHOICE_AUTO},
+		{"explicit none", `{"model": "deepseek-chat", ` + tools + `, "tool_choice": "none", "messages": [{"role": "user", "content": "Hello"}]}`, deepseek_api.TOOL_CHOICE_NONE},
+		{"no tools", `{"model": "deepseek-chat", "messages": [{"role": "user", "content": "Hello"}]}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tool_choice any
+			client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+				body := map[string]any{}
+				json.NewDecoder(r.Body).Decode(&body)
+				tool_choice = body["tool_choice"]
+				writeTestChatResponse(w, "Hello")
+			})
+
+			server := httptest.NewServer(deepseek_api.NewDeepSeekProxy(client, deepseek_api.WithDeepSeekProxyTenant(deepseek_api.DeepSeekProxyTenant{Name: "team-a", VirtualKey: "vk-team-a"})))
+			t.Cleanup(server.Close)
+
+			resp, body := doTestProxyRequest(t, server, http.MethodPost, deepseek_api.PROXY_CHAT_PATH, "vk-team-a", tt.body)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status %d, but got %d: %s", http.StatusOK, resp.StatusCode, body)
+			}
+
+			if tt.expected == "" && tool_choice != nil {
+				t.Errorf("Expected tool_choice to be omitted, but got %v", tool_choice)
+			}
+			if tt.expected != "" && tool_choice != tt.expected {
+				t.Errorf("Expected tool_choice %s, but got %v", tt.expected, tool_choice)
+			}
+		})
+	}
+}
+
+func TestDeepSeekProxy_ClientDisconnect(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"completions", http.MethodPost, deepseek_api.PROXY_COMPLETIONS_PATH, `{"model": "deepseek-chat", "prompt": "Once upon a time"}`},
+		{"models", http.MethodGet, deepseek_api.PROXY_MODELS_PATH, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cancelled := make(chan struct{}, 1)
+			client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+				select {
+				case <-r.Context().Done():
+					cancelled <- struct{}{}
+				case <-time.After(time.Second):
+				}
+			})
+
+			server := httptest.NewServer(deepseek_api.NewDeepSeekProxy(client, deepseek_api.WithDeepSeekProxyTenant(deepseek_api.DeepSeekProxyTenant{Name: "team-a", VirtualKey: "vk-team-a"})))
+			t.Cleanup(server.Close)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			req, _ := http.NewRequestWithContext(ctx, tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer vk-team-a")
+			if resp, err := server.Client().Do(req); err == nil {
+				resp.Body.Close()
+			}
+
+			select {
+			case <-cancelled:
+			case <-time.After(500 * time.Millisecond):
+				t.Error("Expected client disconnect to cancel the upstream request")
+			}
+		})
+	}
+}
+
+func TestDeepSeekProxy_ModelRegistry(t *testing.T) {
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeTestChatResponse(w, "Hello")
+	})
+
+	capability := deepseek_api.DEFAULT_MODEL_CAPABILITY
+	capability.Id = "deepseek-long"
+	capability.MaxOutputTokens = 32768
+	capability.SupportsFIM = false
+	client.SetModelRegistry(deepseek_api.NewModelRegistry(capability))
+
+	server := httptest.NewServer(deepseek_api.NewDeepSeekProxy(client, deepseek_api.WithDeepSeekProxyTenant(deepseek_api.DeepSeekProxyTenant{Name: "team-a", VirtualKey: "vk-team-a"})))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name        string
+		path        string
+		body        string
+		status_code int
+	}{
+		{"chat within client limit", deepseek_api.PROXY_CHAT_PATH, `{"model": "deepseek-long", "max_tokens": 20000, "messages": [{"role": "user", "content": "Hello"}]}`, http.StatusOK},
+		{"chat over client limit", deepseek_api.PROXY_CHAT_PATH, `{"model": "deepseek-long", "max_tokens": 40000, "messages": [{"role": "user", "content": "Hello"}]}`, http.StatusBadRequest},
+		{"completions unsupported by client model", deepseek_api.PROXY_COMPLETIONS_PATH, `{"model": "deepseek-long", "prompt": "Once upon a time"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doTestProxyRequest(t, server, http.MethodPost, tt.path, "vk-team-a", tt.body)
+			if resp.StatusCode != tt.status_code {
+				t.Errorf("Expected status %d, but got %d: %s", tt.status_code, resp.StatusCode, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package deepseek_api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestDeepSeekClient_Chat_Reasoner(t *testing.T) {
+	var body map[string]any
+	server_client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{
+			"id": "chatcmpl-test",
+			"object": "chat.completion",
+			"model": "deepseek-reasoner",
+			"choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "42", "reasoning_content": "Let me think."}}],
+			"usage": {"prompt_tokens": 10, "completion_tokens": 30, "total_tokens": 40, "completion_tokens_details": {"reasoning_tokens": 25}}
+		}`))
+	})
+
+	var warnings []string
+	client := deepseek_api.NewDeepSeekClient(
+		deepseek_api.WithDeepSeekClientCommunication(server_client.GetProtocol(), server_client.GetHost()),
+		deepseek_api.WithDeepSeekClientApi(server_client.GetApi()),
+		deepseek_api.WithDeepSeekClientHttpClient(server_client.GetHttpClient()),
+		deepseek_api.WithDeepSeekClientWarningHandler(func(warning string) {
+			warnings = append(warnings, warning)
+		}),
+	)
+
+	previous_answer := &deepseek_api.ResponseMessage{
+		BasicMessage:     deepseek_api.BasicMessage{Role: deepseek_api.ROLE_ASSISTANT, Content: "41"},
+		ReasoningContent: "Previous thoughts.",
+	}
+	messages := []deepseek_api.DeepSeekMessage{
+		&deepseek_api.UserMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_USER, Content: "Guess a number."}},
+		previous_answer,
+		&deepseek_api.UserMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_USER, Content: "Try again."}},
+	}
+
+	request := deepseek_api.NewDeepSeekChatRequest(messages, deepseek_api.MODEL_DEEPSEEK_REASONER)
+	request.Temperature = 0.2
+
+	chat_response, err := client.Chat(request)
+	if err != nil {
+		t.Fatalf("Chat error: %v", err)
+	}
+
+	sent_message := body["messages"].([]any)[1].(map[string]any)
+	if reasoning, ok := sent_message["reasoning_content"]; ok && reasoning != "" {
+		t.Errorf("Expected reasoning_content to be stripped, but got %v", reasoning)
+	}
+	if previous_answer.ReasoningContent == "" {
+		t.Error("Caller message was modified")
+	}
+	if len(warnings) != 1 || warnings[0] != "temperature has no effect on deepseek-reasoner" {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+
+	result := chat_response.ReasonerResult()
+	if result.Content != "42" || result.ReasoningContent != "Let me think." || result.ReasoningTokens != 25 {
+		t.Errorf("Unexpected reasoner result: %+v", result)
+	}
+
+	request.Logprobs = true
+	if _, err := client.Chat(request); err == nil || err.Error() != "logprobs is not supported by deepseek-reasoner" {
+		t.Errorf("Expected logprobs error, but got %v", err)
+	}
+}
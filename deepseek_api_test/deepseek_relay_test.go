@@ -0,0 +1,136 @@
+package deepseek_api_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestDeepSeekClient_RelayChatStream(t *testing.T) {
+	tests := []struct {
+		format       string
+		content_type string
+		expected     []string
+	}{
+		{
+			format:       deepseek_api.RELAY_FORMAT_SSE,
+			content_type: "text/event-stream",
+			expected:     []string{"data: {", "data: {", "event: done", `data: {"object":"chat.completion.done","finish_reason":"stop","usage":{`},
+		},
+		{
+			format:       deepseek_api.RELAY_FORMAT_NDJSON,
+			content_type: "application/x-ndjson",
+			expected:     []string{`{"id":"chatcmpl-test"`, `{"id":"chatcmpl-test"`, `{"object":"chat.completion.done","finish_reason":"stop","usage":{`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, `data: {"id":"chatcmpl-test","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"Hel"},"finish_reason":null}]}`+"\n\n")
+				fmt.Fprint(w, `data: {"id":"chatcmpl-test","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"lo"},"finish_reason":"stop"}]}`+"\n\n")
+				fmt.Fprint(w, `data: {"id":"chatcmpl-test","object":"chat.completion.chunk","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`+"\n\n")
+				fmt.Fprint(w, "data: [DONE]\n\n")
+			})
+
+			request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+			recorder := httptest.NewRecorder()
+
+			result, err := client.RelayChatStream(context.Background(), recorder, request, tt.format)
+			if err != nil {
+				t.Fatalf("RelayChatStream error: %v", err)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.content_type {
+				t.Errorf("Expected content type %s, but got %s", tt.content_type, recorder.Header().Get("Content-Type"))
+			}
+			if !recorder.Flushed {
+				t.Error("Expected response to be flushed")
+			}
+			if result.Chunks != 2 || result.FinishReason != "stop" || result.Usage.TotalTokens != 15 {
+				t.Errorf("Unexpected result: %+v", result)
+			}
+
+			var lines []string
+			for _, line := range strings.Split(recorder.Body.String(), "\n") {
+				if line != "" {
+					lines = append(lines, line)
+				}
+			}
+			if len(lines) != len(tt.expected) {
+				t.Fatalf("Expected %d lines, but got %q", len(tt.expected), lines)
+			}
+			for i, prefix := range tt.expected {
+				if !strings.HasPrefix(lines[i], prefix) {
+					t.Errorf("Expected line %d to start with %s, but got %s", i, prefix, lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDeepSeekClient_RelayChatStreamDisconnect(t *testing.T) {
+	upstream_cancelled := make(chan struct{})
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for {
+			chunk, _ := json.Marshal(map[string]any{
+				"id":      "chatcmpl-test",
+				"object":  deepseek_api.OBJECT_CHAT_COMPLETION_CHUNK,
+				"choices": []map[string]any{{"index": 0, "delta": map[string]any{"content": "tick"}}},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+			w.(http.Flusher).Flush()
+
+			select {
+			case <-r.Context().Done():
+				close(upstream_cancelled)
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	})
+
+	relay_err := make(chan error, 1)
+	browser := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+		_, err := client.RelayChatStream(r.Context(), w, request, deepseek_api.RELAY_FORMAT_SSE)
+		relay_err <- err
+	}))
+	defer browser.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, browser.URL, nil)
+	resp, err := browser.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Request error: %v", err)
+	}
+
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	if !strings.HasPrefix(line, "data: ") {
+		t.Fatalf("Expected a relayed event, but got %q", line)
+	}
+	cancel()
+	resp.Body.Close()
+
+	select {
+	case <-upstream_cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected upstream request to be cancelled after disconnect")
+	}
+
+	if err := <-relay_err; err == nil {
+		t.Error("Expected relay to report the disconnect")
+	}
+}
@@ -1,7 +1,9 @@
 package deepseek_api_test
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
 	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
@@ -496,3 +498,135 @@ func TestDeepSeekCompletionsRequest_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestDeepSeekChatRequest_PrefixMessage(t *testing.T) {
+	user_message := &deepseek_api.UserMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_USER, Content: "Hello"}}
+	prefix_message := &deepseek_api.AssistantMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_ASSISTANT, Content: "Hi"}, Prefix: true}
+	assistant_message := &deepseek_api.AssistantMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_ASSISTANT, Content: "Hi"}}
+
+	tests := []struct {
+		name     string
+		messages []deepseek_api.DeepSeekMessage
+		expected error
+	}{
+		{
+			name:     "Valid Prefix",
+			messages: []deepseek_api.DeepSeekMessage{user_message, prefix_message},
+			expected: nil,
+		},
+		{
+			name:     "Missing Prefix",
+			messages: []deepseek_api.DeepSeekMessage{user_message, assistant_message},
+			expected: errors.New("last message must be an assistant message with prefix set to true"),
+		},
+		{
+			name:     "Early Prefix",
+			messages: []deepseek_api.DeepSeekMessage{prefix_message, user_message, prefix_message},
+			expected: errors.New("messages[0] must not be a prefix message, only the last message can be"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := deepseek_api.NewDeepSeekChatRequest(test.messages, deepseek_api.MODEL_DEEPSEEK_CHAT)
+			_, err := request.PrefixMessage()
+			if err != nil && test.expected == nil {
+				t.Errorf("Unexpected error: %v", err)
+			} else if err == nil && test.expected != nil {
+				t.Errorf("Expected error: %v, but got none", test.expected)
+			} else if err != nil && test.expected != nil && err.Error() != test.expected.Error() {
+				t.Errorf("Expected error: %v, but got: %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestDeepSeekChatRequest_MarshalJSON(t *testing.T) {
+	messages := []deepseek_api.DeepSeekMessage{
+		&deepseek_api.SystemMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_SYSTEM, Content: "Be brief."}},
+		&deepseek_api.AssistantMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_ASSISTANT, Content: "Hi"}},
+	}
+
+	request := deepseek_api.NewDeepSeekChatRequest(messages, deepseek_api.MODEL_DEEPSEEK_CHAT)
+	request.Temperature = 0
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	expected := `{"messages":[{"content":"Be brief.","role":"system"},{"content":"Hi","role":"assistant"}],"model":"deepseek-chat","frequency_penalty":0,"max_tokens":4096,"presence_penalty":0,"response_format":{"type":"text"},"temperature":0,"top_p":1}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, but got %s", expected, data)
+	}
+
+	top_logprobs := int64(0)
+	request.Logprobs = true
+	request.TopLogprobs = &top_logprobs
+	data, _ = json.Marshal(request)
+	if !strings.Contains(string(data), `"logprobs":true,"top_logprobs":0`) {
+		t.Errorf("Expected explicit top_logprobs 0 to be kept, but got %s", data)
+	}
+}
+
+func TestDeepSeekCompletionsRequest_MarshalJSON(t *testing.T) {
+	request := deepseek_api.NewDeepSeekCompletionsRequest(deepseek_api.MODEL_DEEPSEEK_CHAT, "def fib(n):")
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	expected := `{"model":"deepseek-chat","prompt":"def fib(n):","frequency_penalty":0,"logprobs":0,"max_tokens":1024,"presence_penalty":0,"temperature":1,"top_p":1}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, but got %s", expected, data)
+	}
+
+	suffix := ""
+	request.Suffix = &suffix
+	data, _ = json.Marshal(request)
+	if !strings.Contains(string(data), `"suffix":""`) {
+		t.Errorf("Expected explicit empty suffix to be kept, but got %s", data)
+	}
+}
+
+func TestDeepSeekChatRequest_UnmarshalJSON(t *testing.T) {
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).
+		System("Be brief.").
+		User("What is the weather?").
+		Message(&deepseek_api.AssistantMessage{
+			BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_ASSISTANT},
+			ToolCalls:    []deepseek_api.ToolCall{newTestToolCall("call_1")},
+		}).
+		ToolResult("call_1", "Sunny").
+		Tools(newTestTool("get_weather", false, map[string]any{"type": "object"})).
+		ToolChoice(deepseek_api.NamedToolChoice("get_weather")).
+		Build()
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	decoded := &deepseek_api.DeepSeekChatRequest{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+
+	if _, ok := decoded.Messages[3].(*deepseek_api.ToolMessage); !ok {
+		t.Errorf("Expected a tool message, but got %T", decoded.Messages[3])
+	}
+	if decoded.ToolChoice.FunctionName() != "get_weather" {
+		t.Errorf("Expected named tool choice, but got %q", decoded.ToolChoice)
+	}
+
+	redata, _ := json.Marshal(decoded)
+	if string(redata) != string(data) {
+		t.Errorf("Expected round trip %s, but got %s", data, redata)
+	}
+
+	err = json.Unmarshal([]byte(`{"model":"deepseek-chat","messages":[{"role":"developer","content":"Hi"}]}`), decoded)
+	if err == nil || !strings.Contains(err.Error(), "messages[0]: unknown message role: developer") {
+		t.Errorf("Expected unknown role error, but got %v", err)
+	}
+}
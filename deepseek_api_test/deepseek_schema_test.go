@@ -0,0 +1,176 @@
+package deepseek_api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+type schemaTestItem struct {
+	Name  string   `json:"name"`
+	Price float64  `json:"price"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+type schemaTestOrder struct {
+	Id    int              `json:"id"`
+	Items []schemaTestItem `json:"items"`
+	Note  *string          `json:"note"`
+}
+
+func TestJSONSchemaOf(t *testing.T) {
+	schema := deepseek_api.JSONSchemaOf[schemaTestOrder]()
+
+	if schema["type"] != deepseek_api.SCHEMA_TYPE_OBJECT {
+		t.Fatalf("Expected object schema, but got %v", schema["type"])
+	}
+	if required := schema["required"]; !reflect.DeepEqual(required, []any{"id", "items"}) {
+		t.Errorf("Unexpected required properties: %v", required)
+	}
+
+	items := schema["properties"].(map[string]any)["items"].(map[string]any)
+	item := items["items"].(map[string]any)
+	if !reflect.DeepEqual(item["required"], []any{"name", "price"}) {
+		t.Errorf("Unexpected item required properties: %v", item["required"])
+	}
+}
+
+type schemaTestBase struct {
+	Id        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type schemaTestAudit struct {
+	Actor string `json:"actor"`
+}
+
+type schemaTestRecord struct {
+	schemaTestBase
+	*schemaTestAudit
+	Name    string `json:"name"`
+	Payload []byte `json:"payload"`
+}
+
+func TestJSONSchemaOf_RoundTrip(t *testing.T) {
+	schema := deepseek_api.JSONSchemaOf[schemaTestRecord]()
+
+	properties := schema["properties"].(map[string]any)
+	for _, name := range []string{"id", "created_at", "actor", "name", "payload"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("Expected promoted property %s, but got %v", name, properties)
+		}
+	}
+	if required := schema["required"]; !reflect.DeepEqual(required, []any{"id", "created_at", "name", "payload"}) {
+		t.Errorf("Unexpected required properties: %v", required)
+	}
+
+	record := schemaTestRecord{
+		schemaTestBase:  schemaTestBase{Id: 1, CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		schemaTestAudit: &schemaTestAudit{Actor: "alice"},
+		Name:            "order",
+		Payload:         []byte("raw"),
+	}
+
+	for _, value := range []schemaTestRecord{record, {Name: "empty", Payload: []byte{}}} {
+		data, _ := json.Marshal(value)
+
+		var decoded any
+		json.Unmarshal(data, &decoded)
+		if err := deepseek_api.ValidateJSONSchema(schema, decoded); err != nil {
+			t.Errorf("Expected %s to match its own schema, but got %v", data, err)
+		}
+	}
+}
+
+func TestValidateJSONSchema(t *testing.T) {
+	schema := deepseek_api.JSONSchemaOf[schemaTestOrder]()
+
+	tests := []struct {
+		name     string
+		value    any
+		expected []string
+	}{
+		{
+			name:     "Valid",
+			value:    map[string]any{"id": float64(1), "items": []any{map[string]any{"name": "tea", "price": 1.5}}, "note": nil},
+			expected: nil,
+		},
+		{
+			name:     "Missing And Wrong Types",
+			value:    map[string]any{"id": 1.5, "items": []any{map[string]any{"name": float64(3)}}, "extra": true},
+			expected: []string{"$.extra", "$.id", "$.items[0].price", "$.items[0].name"},
+		},
+		{
+			name:     "Wrong Root",
+			value:    []any{},
+			expected: []string{"$"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := deepseek_api.ValidateJSONSchema(schema, test.value)
+			if test.expected == nil {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			var validation_err *deepseek_api.SchemaValidationError
+			if !errors.As(err, &validation_err) {
+				t.Fatalf("Expected SchemaValidationError, but got %v", err)
+			}
+
+			var paths []string
+			for _, violation := range validation_err.Violations {
+				paths = append(paths, violation.Path)
+			}
+			if !reflect.DeepEqual(paths, test.expected) {
+				t.Errorf("Expected violations at %v, but got %v", test.expected, paths)
+			}
+		})
+	}
+
+	enum_schema := map[string]any{"type": "string", "enum": []any{"red", "green"}, "maxLength": 4}
+	if err := deepseek_api.ValidateJSONSchema(enum_schema, "green"); err == nil {
+		t.Error("Expected maxLength violation, but got none")
+	}
+}
+
+func TestChatJSON_Schema(t *testing.T) {
+	replies := []string{`{"id": 7, "items": [{"name": "tea"}]}`, `{"id": 7, "items": []}`}
+	count := 0
+
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeTestChatResponse(w, replies[count])
+		count++
+	})
+
+	messages := []deepseek_api.DeepSeekMessage{&deepseek_api.UserMessage{BasicMessage: deepseek_api.BasicMessage{Role: deepseek_api.ROLE_USER, Content: "Reply in json."}}}
+	request := deepseek_api.NewDeepSeekChatRequest(messages, deepseek_api.MODEL_DEEPSEEK_CHAT)
+	request.ResponseFormat = deepseek_api.ResponseFormat{Type: deepseek_api.RESPONSE_FORMAT_JSON_OBJECT, Schema: deepseek_api.JSONSchemaOf[schemaTestOrder]()}
+
+	_, _, err := deepseek_api.ChatJSON[schemaTestOrder](context.Background(), client, request, 1)
+	var validation_err *deepseek_api.SchemaValidationError
+	if !errors.As(err, &validation_err) {
+		t.Fatalf("Expected SchemaValidationError, but got %v", err)
+	}
+	if len(validation_err.Violations) != 1 || validation_err.Violations[0].Path != "$.items[0].price" {
+		t.Errorf("Unexpected violations: %v", validation_err.Violations)
+	}
+
+	order, _, err := deepseek_api.ChatJSON[schemaTestOrder](context.Background(), client, request, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if order.Id != 7 {
+		t.Errorf("Unexpected order: %+v", order)
+	}
+}
@@ -0,0 +1,110 @@
+package deepseek_api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestDeepSeekClient_StreamChat(t *testing.T) {
+	tests := []struct {
+		name     string
+		events   []string
+		expected []string
+	}{
+		{
+			name: "reasoner content",
+			events: []string{
+				`{"choices":[{"index":0,"delta":{"role":"assistant","reasoning_content":"Think"},"finish_reason":null}]}`,
+				`{"choices":[{"index":0,"delta":{"reasoning_content":"ing"},"finish_reason":null}]}`,
+				`{"choices":[{"index":0,"delta":{"content":"Answer"},"finish_reason":"stop"}]}`,
+				`{"choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`,
+			},
+			expected: []string{"reasoning:Think", "reasoning:ing", "content:Answer", "finish:stop:15"},
+		},
+		{
+			name: "tool calls",
+			events: []string{
+				`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]},"finish_reason":null}]}`,
+				`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]},"finish_reason":null}]}`,
+				`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]},"finish_reason":null}]}`,
+				`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_2","type":"function","function":{"name":"get_time","arguments":"{}"}}]},"finish_reason":"tool_calls"}]}`,
+			},
+			expected: []string{"start:call_1:get_weather", `args:call_1:{"city":`, `args:call_1:"Paris"}`, "start:call_2:get_time", "args:call_2:{}", "finish:tool_calls:0"},
+		},
+		{
+			name:     "error event",
+			events:   []string{`{"error":{"message":"Server overloaded","type":"server_error"}}`},
+			expected: []string{"error:deepseek error: Server overloaded"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				for _, event := range tt.events {
+					fmt.Fprintf(w, "data: %s\n\n", event)
+				}
+				fmt.Fprint(w, "data: [DONE]\n\n")
+			})
+
+			var events []string
+			handler := &deepseek_api.DeepSeekStreamHandler{
+				OnContent:   func(delta string) { events = append(events, "content:"+delta) },
+				OnReasoning: func(delta string) { events = append(events, "reasoning:"+delta) },
+				OnToolCallStart: func(id string, name string) {
+					events = append(events, "start:"+id+":"+name)
+				},
+				OnToolCallArgs: func(id string, delta string) {
+					events = append(events, "args:"+id+":"+delta)
+				},
+				OnFinish: func(finish_reason string, usage *deepseek_api.Usage) {
+					total_tokens := int64(0)
+					if usage != nil {
+						total_tokens = usage.TotalTokens
+					}
+					events = append(events, fmt.Sprintf("finish:%s:%d", finish_reason, total_tokens))
+				},
+				OnError: func(err error) { events = append(events, "error:"+err.Error()) },
+			}
+
+			request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_REASONER).User("Hello").Build()
+			client.StreamChat(context.Background(), request, handler)
+
+			if strings.Join(events, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Expected events %q, but got %q", tt.expected, events)
+			}
+		})
+	}
+}
+
+func TestDeepSeekClient_StreamChatJSON(t *testing.T) {
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeTestChatStream(w, `{"title": "Wea`, `ther", "tags": [`, `"sun"]}`)
+	})
+
+	var partials []string
+	handler := &deepseek_api.DeepSeekStreamHandler{
+		OnContentJSON: func(partial any) {
+			data, _ := json.Marshal(partial)
+			partials = append(partials, string(data))
+		},
+	}
+
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").JSON().Build()
+	if err := client.StreamChat(context.Background(), request, handler); err != nil {
+		t.Fatalf("StreamChat error: %v", err)
+	}
+
+	expected := []string{`{"title":"Wea"}`, `{"tags":[],"title":"Weather"}`, `{"tags":["sun"],"title":"Weather"}`}
+	if strings.Join(partials, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected partials %q, but got %q", expected, partials)
+	}
+}
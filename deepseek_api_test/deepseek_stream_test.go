@@ -0,0 +1,133 @@
+package deepseek_api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func writeTestChatStream(w http.ResponseWriter, deltas ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	flusher := w.(http.Flusher)
+
+	fmt.Fprint(w, ": keep-alive\n\n")
+	for _, delta := range deltas {
+		chunk, _ := json.Marshal(map[string]any{
+			"id":      "chatcmpl-test",
+			"object":  deepseek_api.OBJECT_CHAT_COMPLETION_CHUNK,
+			"model":   deepseek_api.MODEL_DEEPSEEK_CHAT,
+			"choices": []map[string]any{{"index": 0, "delta": map[string]any{"content": delta}, "finish_reason": nil}},
+		})
+		fmt.Fprintf(w, "data: %s\n\n", chunk)
+		flusher.Flush()
+	}
+	fmt.Fprint(w, `data: {"id":"chatcmpl-test","object":"chat.completion.chunk","model":"deepseek-chat","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`+"\n\n")
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+func TestDeepSeekStreamReader_Next(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		err      error
+	}{
+		{
+			name:     "events and done",
+			input:    ": keep-alive\n\ndata: {\"a\":1}\n\ndata: {\"b\":2}\r\n\r\ndata: [DONE]\n\n",
+			expected: []string{`{"a":1}`, `{"b":2}`},
+			err:      io.EOF,
+		},
+		{
+			name:     "multi-line event",
+			input:    "data: first\ndata: second\n\ndata: [DONE]\n\n",
+			expected: []string{"first\nsecond"},
+			err:      io.EOF,
+		},
+		{
+			name:     "truncated stream",
+			input:    "data: {\"a\":1}\n\n",
+			expected: []string{`{"a":1}`},
+			err:      io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := deepseek_api.NewDeepSeekStreamReader(io.NopCloser(strings.NewReader(tt.input)))
+
+			var events []string
+			var err error
+			for {
+				var data []byte
+				data, err = reader.Next()
+				if err != nil {
+					break
+				}
+				events = append(events, string(data))
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected error %v, but got %v", tt.err, err)
+			}
+			if strings.Join(events, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Expected events %q, but got %q", tt.expected, events)
+			}
+		})
+	}
+}
+
+func TestDeepSeekClient_ChatStream(t *testing.T) {
+	var stream bool
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]any{}
+		json.NewDecoder(r.Body).Decode(&body)
+		stream, _ = body["stream"].(bool)
+		writeTestChatStream(w, "Hel", "lo")
+	})
+
+	request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+
+	chat_stream, err := client.ChatStream(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ChatStream error: %v", err)
+	}
+	defer chat_stream.Close()
+
+	var content strings.Builder
+	var usage *deepseek_api.Usage
+	for {
+		chunk, err := chat_stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatalf("Recv error: %v", err)
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+	}
+
+	if !stream {
+		t.Error("Expected stream to be requested")
+	}
+	if content.String() != "Hello" {
+		t.Errorf("Expected content %q, but got %q", "Hello", content.String())
+	}
+	if usage == nil || usage.TotalTokens != 15 {
+		t.Errorf("Expected usage chunk, but got %+v", usage)
+	}
+	if request.Stream {
+		t.Error("Expected caller request to be unchanged")
+	}
+}
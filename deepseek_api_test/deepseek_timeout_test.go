@@ -0,0 +1,191 @@
+package deepseek_api_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func TestDeepSeekClient_StreamTimeouts(t *testing.T) {
+	event := `{"choices":[{"index":0,"delta":{"content":"tick"},"finish_reason":null}]}`
+
+	tests := []struct {
+		name     string
+		timeouts deepseek_api.StreamTimeouts
+		handler  func(w http.ResponseWriter, r *http.Request)
+		expected error
+	}{
+		{
+			name:     "connect",
+			timeouts: deepseek_api.StreamTimeouts{Connect: 50 * time.Millisecond},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			expected: deepseek_api.ErrStreamConnectTimeout,
+		},
+		{
+			name:     "first token",
+			timeouts: deepseek_api.StreamTimeouts{FirstToken: 50 * time.Millisecond},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				for i := 0; i < 50; i++ {
+					fmt.Fprint(w, ": keep-alive\n\n")
+					w.(http.Flusher).Flush()
+					select {
+					case <-r.Context().Done():
+						return
+					case <-time.After(10 * time.Millisecond):
+					}
+				}
+			},
+			expected: deepseek_api.ErrStreamFirstTokenTimeout,
+		},
+		{
+			name:     "idle",
+			timeouts: deepseek_api.StreamTimeouts{Idle: 50 * time.Millisecond},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, "data: %s\n\n", event)
+				w.(http.Flusher).Flush()
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			expected: deepseek_api.ErrStreamIdleTimeout,
+		},
+		{
+			name:     "total",
+			timeouts: deepseek_api.StreamTimeouts{Idle: 50 * time.Millisecond, Total: 100 * time.Millisecond},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				for i := 0; i < 100; i++ {
+					fmt.Fprintf(w, "data: %s\n\n", event)
+					w.(http.Flusher).Flush()
+					select {
+					case <-r.Context().Done():
+						return
+					case <-time.After(10 * time.Millisecond):
+					}
+				}
+			},
+			expected: deepseek_api.ErrStreamTotalTimeout,
+		},
+		{
+			name:     "long healthy stream outlives client timeout",
+			timeouts: deepseek_api.StreamTimeouts{Idle: 100 * time.Millisecond},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				for i := 0; i < 10; i++ {
+					fmt.Fprintf(w, "data: %s\n\n", event)
+					w.(http.Flusher).Flush()
+					time.Sleep(10 * time.Millisecond)
+				}
+				fmt.Fprint(w, "data: [DONE]\n\n")
+			},
+			expected: io.EOF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+				tt.handler(w, r)
+			})
+			client.SetStreamTimeouts(tt.timeouts)
+			client.GetHttpClient().Timeout = 50 * time.Millisecond
+
+			request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+
+			start := time.Now()
+			stream, err := client.ChatStream(context.Background(), request)
+			if err == nil {
+				defer stream.Close()
+				for err == nil {
+					_, err = stream.Recv()
+				}
+			}
+
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("Expected %v, but got %v", tt.expected, err)
+			}
+			if time.Since(start) > 500*time.Millisecond {
+				t.Errorf("Expected stream to end promptly, but took %s", time.Since(start))
+			}
+
+			var timeout_error *deepseek_api.StreamTimeoutError
+			if tt.expected != io.EOF && (!errors.As(err, &timeout_error) || timeout_error.Duration <= 0) {
+				t.Errorf("Expected a typed timeout error with its duration, but got %#v", err)
+			}
+		})
+	}
+}
+
+func TestDeepSeekClient_StreamTimeoutsTripBreaker(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts deepseek_api.StreamTimeouts
+		handler  func(w http.ResponseWriter, r *http.Request)
+	}{
+		{
+			name:     "connect",
+			timeouts: deepseek_api.StreamTimeouts{Connect: 50 * time.Millisecond},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+		},
+		{
+			name:     "first token",
+			timeouts: deepseek_api.StreamTimeouts{FirstToken: 50 * time.Millisecond},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.(http.Flusher).Flush()
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+				tt.handler(w, r)
+			})
+			client.SetStreamTimeouts(tt.timeouts)
+			client.SetCircuitBreaker(deepseek_api.NewCircuitBreaker(deepseek_api.WithCircuitBreakerFailureThreshold(2)))
+
+			request, _ := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Build()
+
+			for i := 0; i < 2; i++ {
+				stream, err := client.ChatStream(context.Background(), request)
+				if err == nil {
+					for err == nil {
+						_, err = stream.Recv()
+					}
+					stream.Close()
+				}
+
+				var timeout_error *deepseek_api.StreamTimeoutError
+				if !errors.As(err, &timeout_error) {
+					t.Fatalf("Request %d: expected a stream timeout, but got %v", i, err)
+				}
+			}
+
+			if state := client.GetCircuitBreaker().State(); state != deepseek_api.CIRCUIT_OPEN {
+				t.Errorf("Expected stream timeouts to open the breaker, but got %s", state)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package deepseek_api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	deepseek_api "github.com/ZSLTChenXiYin/deepseek-api"
+)
+
+func newTestTool(name string, strict bool, parameters map[string]any) deepseek_api.Tool {
+	tool := deepseek_api.Tool{Type: deepseek_api.TOOL_TYPE_FUNCTION}
+	tool.Function.Name = name
+	tool.Function.Strict = strict
+	tool.Function.Parameters = parameters
+	return tool
+}
+
+func TestToolChoice_JSON(t *testing.T) {
+	tests := []struct {
+		choice   deepseek_api.ToolChoice
+		expected string
+	}{
+		{deepseek_api.TOOL_CHOICE_AUTO, `"auto"`},
+		{deepseek_api.NamedToolChoice("get_weather"), `{"type":"function","function":{"name":"get_weather"}}`},
+	}
+
+	for _, test := range tests {
+		data, err := json.Marshal(test.choice)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+		if string(data) != test.expected {
+			t.Errorf("Expected %s, but got %s", test.expected, data)
+		}
+
+		var decoded deepseek_api.ToolChoice
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		if decoded != test.choice {
+			t.Errorf("Expected %v, but got %v", test.choice, decoded)
+		}
+	}
+}
+
+func TestToolChoice_Named(t *testing.T) {
+	named := deepseek_api.NamedToolChoice("get_weather")
+	spoofed := deepseek_api.ToolChoice("function:get_weather")
+
+	if named == spoofed {
+		t.Fatal("Expected a named tool choice to differ from a plain mode string")
+	}
+	if named.FunctionName() != "get_weather" || spoofed.FunctionName() != "" {
+		t.Errorf("Unexpected function names %q and %q", named.FunctionName(), spoofed.FunctionName())
+	}
+	if named.String() != "function:get_weather" {
+		t.Errorf("Expected readable named choice, but got %q", named.String())
+	}
+
+	tool := newTestTool("get_weather", false, nil)
+	if _, err := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Tools(tool).ToolChoice(spoofed).Build(); err == nil {
+		t.Error("Expected a plain mode string to be rejected as a tool choice")
+	}
+}
+
+func TestDeepSeekChatRequest_Validation_Tools(t *testing.T) {
+	strict_parameters := map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{"city": map[string]any{"type": "string"}},
+		"required":             []any{"city"},
+		"additionalProperties": false,
+	}
+	loose_parameters := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"city": map[string]any{"type": "string"}},
+	}
+
+	tests := []struct {
+		name     string
+		tools    []deepseek_api.Tool
+		choice   deepseek_api.ToolChoice
+		expected string
+	}{
+		{
+			name:     "Named Choice",
+			tools:    []deepseek_api.Tool{newTestTool("get_weather", true, strict_parameters)},
+			choice:   deepseek_api.NamedToolChoice("get_weather"),
+			expected: "",
+		},
+		{
+			name:     "Unknown Named Choice",
+			tools:    []deepseek_api.Tool{newTestTool("get_weather", false, loose_parameters)},
+			choice:   deepseek_api.NamedToolChoice("get_time"),
+			expected: "tool_choice function get_time must be defined in tools",
+		},
+		{
+			name:     "Loose Strict Schema",
+			tools:    []deepseek_api.Tool{newTestTool("get_weather", true, loose_parameters)},
+			choice:   deepseek_api.TOOL_CHOICE_AUTO,
+			expected: "tools[0].function.parameters must set additionalProperties to false in strict mode; tools[0].function.parameters.city must be required in strict mode",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request, err := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Tools(test.tools...).ToolChoice(test.choice).Build()
+			if test.expected == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				} else if request.ToolChoice.FunctionName() != "get_weather" {
+					t.Errorf("Unexpected tool_choice: %v", request.ToolChoice)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("Expected error: %v, but got: %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestDeepSeekClient_Chat_StrictTools(t *testing.T) {
+	var path string
+	var body map[string]any
+	client := newTestDeepSeekClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+		writeTestChatResponse(w, "")
+	})
+
+	tool := newTestTool("get_weather", true, map[string]any{"type": "object", "properties": map[string]any{}, "required": []any{}, "additionalProperties": false})
+	request, err := deepseek_api.NewChat(deepseek_api.MODEL_DEEPSEEK_CHAT).User("Hello").Tools(tool).ToolChoice(deepseek_api.NamedToolChoice("get_weather")).Build()
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+
+	if _, err := client.Chat(request); err != nil {
+		t.Fatalf("Chat error: %v", err)
+	}
+
+	if path != deepseek_api.DEFAULT_BETA_CHAT_PATH {
+		t.Errorf("Expected strict tools to use %s, but got %s", deepseek_api.DEFAULT_BETA_CHAT_PATH, path)
+	}
+	if choice, ok := body["tool_choice"].(map[string]any); !ok || choice["type"] != deepseek_api.TOOL_TYPE_FUNCTION {
+		t.Errorf("Expected named tool_choice object, but got %v", body["tool_choice"])
+	}
+	if strict := body["tools"].([]any)[0].(map[string]any)["function"].(map[string]any)["strict"]; strict != true {
+		t.Errorf("Expected strict flag to be sent, but got %v", strict)
+	}
+}
@@ -0,0 +1,226 @@
+package deepseek_api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+)
+
+const DEFAULT_BATCH_CONCURRENCY = 4
+
+type DeepSeekBatchResult struct {
+	Index    int
+	Response *DeepSeekChatResponse
+	Err      error
+	Resumed  bool
+}
+
+type DeepSeekBatchProgress struct {
+	Completed int
+	Failed    int
+	Resumed   int
+	Total     int
+	Usage     Usage
+}
+
+type batchCheckpoint struct {
+	Index    int                   `json:"index"`
+	Hash     string                `json:"hash"`
+	Response *DeepSeekChatResponse `json:"response"`
+}
+
+type batchItem struct {
+	index   int
+	request *DeepSeekChatRequest
+	hash    string
+}
+
+type DeepSeekBatchRunner struct {
+	client      *DeepSeekClient
+	concurrency int
+	checkpoint  string
+	progress    func(progress DeepSeekBatchProgress)
+}
+
+type DeepSeekBatchRunnerOptions func(*DeepSeekBatchRunner)
+
+func WithDeepSeekBatchRunnerConcurrency(concurrency int) DeepSeekBatchRunnerOptions {
+	return func(dbr *DeepSeekBatchRunner) {
+		dbr.concurrency = concurrency
+	}
+}
+
+func WithDeepSeekBatchRunnerCheckpoint(checkpoint string) DeepSeekBatchRunnerOptions {
+	return func(dbr *DeepSeekBatchRunner) {
+		dbr.checkpoint = checkpoint
+	}
+}
+
+func WithDeepSeekBatchRunnerProgress(progress func(progress DeepSeekBatchProgress)) DeepSeekBatchRunnerOptions {
+	return func(dbr *DeepSeekBatchRunner) {
+		dbr.progress = progress
+	}
+}
+
+func NewDeepSeekBatchRunner(client *DeepSeekClient, options ...DeepSeekBatchRunnerOptions) *DeepSeekBatchRunner {
+	if client == nil {
+		return nil
+	}
+
+	dbr := &DeepSeekBatchRunner{client: client}
+	for _, option := range options {
+		option(dbr)
+	}
+
+	if dbr.concurrency < 1 {
+		dbr.concurrency = DEFAULT_BATCH_CONCURRENCY
+	}
+
+	return dbr
+}
+
+func (dbr *DeepSeekBatchRunner) Run(ctx context.Context, requests []*DeepSeekChatRequest) ([]DeepSeekBatchResult, Usage, error) {
+	request_chan := make(chan *DeepSeekChatRequest, len(requests))
+	for _, request := range requests {
+		request_chan <- request
+	}
+	close(request_chan)
+
+	return dbr.RunChannel(ctx, request_chan)
+}
+
+func (dbr *DeepSeekBatchRunner) RunChannel(ctx context.Context, requests <-chan *DeepSeekChatRequest) ([]DeepSeekBatchResult, Usage, error) {
+	checkpoints, err := loadBatchCheckpoints(dbr.checkpoint)
+	if err != nil {
+		return nil, Usage{}, err
+	}
+
+	var checkpoint_file *os.File
+	if dbr.checkpoint != "" {
+		checkpoint_file, err = os.OpenFile(dbr.checkpoint, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, Usage{}, err
+		}
+		defer checkpoint_file.Close()
+	}
+
+	var mutex sync.Mutex
+	var results []DeepSeekBatchResult
+	var progress DeepSeekBatchProgress
+	var checkpoint_err error
+
+	finish := func(result DeepSeekBatchResult, hash string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		results[result.Index] = result
+		if result.Err != nil {
+			progress.Failed++
+		} else {
+			progress.Completed++
+			progress.Usage.Add(result.Response.Usage)
+		}
+		if result.Resumed {
+			progress.Resumed++
+		}
+
+		if checkpoint_file != nil && result.Err == nil && !result.Resumed && checkpoint_err == nil {
+			checkpoint_err = writeBatchCheckpoint(checkpoint_file, batchCheckpoint{Index: result.Index, Hash: hash, Response: result.Response})
+		}
+
+		if dbr.progress != nil {
+			dbr.progress(progress)
+		}
+	}
+
+	items := make(chan batchItem)
+	var wg sync.WaitGroup
+	for i := 0; i < dbr.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range items {
+				result := DeepSeekBatchResult{Index: item.index}
+				if ctx.Err() != nil {
+					result.Err = ctx.Err()
+				} else {
+					result.Response, result.Err = dbr.client.ChatContext(ctx, item.request)
+				}
+				finish(result, item.hash)
+			}
+		}()
+	}
+
+	index := 0
+	for request := range requests {
+		hash := ""
+		if request != nil {
+			hash, _ = RequestHash(request)
+		}
+
+		mutex.Lock()
+		results = append(results, DeepSeekBatchResult{Index: index})
+		progress.Total++
+		mutex.Unlock()
+
+		if checkpoint, ok := checkpoints[index]; ok && hash != "" && checkpoint.Hash == hash {
+			finish(DeepSeekBatchResult{Index: index, Response: checkpoint.Response, Resumed: true}, hash)
+		} else {
+			items <- batchItem{index: index, request: request, hash: hash}
+		}
+		index++
+	}
+	close(items)
+	wg.Wait()
+
+	if checkpoint_err != nil {
+		return results, progress.Usage, checkpoint_err
+	}
+
+	return results, progress.Usage, ctx.Err()
+}
+
+func loadBatchCheckpoints(checkpoint string) (map[int]batchCheckpoint, error) {
+	checkpoints := map[int]batchCheckpoint{}
+	if checkpoint == "" {
+		return checkpoints, nil
+	}
+
+	file, err := os.Open(checkpoint)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoints, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		entry := batchCheckpoint{}
+		if json.Unmarshal(line, &entry) != nil || entry.Response == nil {
+			continue
+		}
+		checkpoints[entry.Index] = entry
+	}
+
+	return checkpoints, scanner.Err()
+}
+
+func writeBatchCheckpoint(file *os.File, entry batchCheckpoint) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,172 @@
+package deepseek_api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+type DeepSeekBatchJob struct {
+	CustomId string               `json:"custom_id"`
+	Method   string               `json:"method,omitempty"`
+	Url      string               `json:"url,omitempty"`
+	Body     *DeepSeekChatRequest `json:"body"`
+}
+
+type DeepSeekBatchJobError struct {
+	Message    string `json:"message"`
+	StatusCode int    `json:"status_code,omitempty"`
+}
+
+type DeepSeekBatchJobResult struct {
+	CustomId string                 `json:"custom_id"`
+	Response *DeepSeekChatResponse  `json:"response,omitempty"`
+	Error    *DeepSeekBatchJobError `json:"error,omitempty"`
+	Usage    *Usage                 `json:"usage,omitempty"`
+}
+
+type deepSeekBatchJobLine struct {
+	CustomId string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	Url      string          `json:"url"`
+	Body     json.RawMessage `json:"body"`
+}
+
+func ReadDeepSeekBatchJobs(r io.Reader) ([]DeepSeekBatchJob, error) {
+	var jobs []DeepSeekBatchJob
+	custom_ids := map[string]int{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line_number := 1; scanner.Scan(); line_number++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		job, err := parseDeepSeekBatchJob(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line_number, err)
+		}
+
+		if previous, ok := custom_ids[job.CustomId]; ok {
+			return nil, fmt.Errorf("line %d: custom_id %s is already used on line %d", line_number, job.CustomId, previous)
+		}
+		custom_ids[job.CustomId] = line_number
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, scanner.Err()
+}
+
+func parseDeepSeekBatchJob(line []byte) (DeepSeekBatchJob, error) {
+	job_line := deepSeekBatchJobLine{}
+	err := json.Unmarshal(line, &job_line)
+	if err != nil {
+		return DeepSeekBatchJob{}, err
+	}
+
+	if job_line.CustomId == "" {
+		return DeepSeekBatchJob{}, errors.New("custom_id must be set")
+	}
+
+	if job_line.Method != "" && job_line.Method != http.MethodPost {
+		return DeepSeekBatchJob{}, fmt.Errorf("method %s is not supported", job_line.Method)
+	}
+
+	switch job_line.Url {
+	case "", PROXY_CHAT_PATH, DEFAULT_CHAT_PATH:
+	default:
+		return DeepSeekBatchJob{}, fmt.Errorf("url %s is not supported", job_line.Url)
+	}
+
+	if len(job_line.Body) == 0 {
+		return DeepSeekBatchJob{}, errors.New("body must be set")
+	}
+
+	body, err := ParseDeepSeekChatRequest(job_line.Body)
+	if err != nil {
+		return DeepSeekBatchJob{}, err
+	}
+
+	err = body.DeepSeekRequest()
+	if err != nil {
+		return DeepSeekBatchJob{}, err
+	}
+
+	return DeepSeekBatchJob{CustomId: job_line.CustomId, Method: job_line.Method, Url: job_line.Url, Body: body}, nil
+}
+
+func WriteDeepSeekBatchJobResults(w io.Writer, results []DeepSeekBatchJobResult) error {
+	encoder := json.NewEncoder(w)
+	for _, result := range results {
+		err := encoder.Encode(result)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (dbr *DeepSeekBatchRunner) RunJobs(ctx context.Context, jobs []DeepSeekBatchJob) ([]DeepSeekBatchJobResult, Usage, error) {
+	requests := make([]*DeepSeekChatRequest, 0, len(jobs))
+	for _, job := range jobs {
+		requests = append(requests, job.Body)
+	}
+
+	results, usage, err := dbr.Run(ctx, requests)
+
+	job_results := make([]DeepSeekBatchJobResult, 0, len(results))
+	for i, result := range results {
+		job_result := DeepSeekBatchJobResult{CustomId: jobs[i].CustomId}
+		if result.Err != nil {
+			job_result.Error = &DeepSeekBatchJobError{Message: result.Err.Error()}
+
+			var status_error *DeepSeekStatusError
+			if errors.As(result.Err, &status_error) {
+				job_result.Error.StatusCode = status_error.StatusCode
+			}
+		} else {
+			job_result.Response = result.Response
+			job_result.Usage = &result.Response.Usage
+		}
+		job_results = append(job_results, job_result)
+	}
+
+	return job_results, usage, err
+}
+
+func (dbr *DeepSeekBatchRunner) RunJobFile(ctx context.Context, input_path string, output_path string) (Usage, error) {
+	input, err := os.Open(input_path)
+	if err != nil {
+		return Usage{}, err
+	}
+	defer input.Close()
+
+	jobs, err := ReadDeepSeekBatchJobs(input)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	results, usage, run_err := dbr.RunJobs(ctx, jobs)
+
+	output, err := os.Create(output_path)
+	if err != nil {
+		return usage, err
+	}
+	defer output.Close()
+
+	err = WriteDeepSeekBatchJobResults(output, results)
+	if err != nil {
+		return usage, err
+	}
+
+	return usage, run_err
+}
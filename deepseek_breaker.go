@@ -0,0 +1,275 @@
+package deepseek_api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_BREAKER_FAILURE_THRESHOLD = 5
+	DEFAULT_BREAKER_OPEN_TIMEOUT      = 30 * time.Second
+	DEFAULT_BREAKER_MIN_REQUESTS      = 10
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type CircuitState int
+
+const (
+	CIRCUIT_CLOSED CircuitState = iota
+	CIRCUIT_OPEN
+	CIRCUIT_HALF_OPEN
+)
+
+func (cs CircuitState) String() string {
+	switch cs {
+	case CIRCUIT_CLOSED:
+		return "closed"
+	case CIRCUIT_OPEN:
+		return "open"
+	case CIRCUIT_HALF_OPEN:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+type circuitOutcome struct {
+	at     time.Time
+	failed bool
+}
+
+type CircuitBreaker struct {
+	mutex sync.Mutex
+
+	state                CircuitState
+	consecutive_failures int
+	outcomes             []circuitOutcome
+	opened_at            time.Time
+	probing              bool
+
+	failure_threshold int
+	error_rate        float64
+	window            time.Duration
+	min_requests      int
+	open_timeout      time.Duration
+
+	on_state_change func(from CircuitState, to CircuitState)
+}
+
+type CircuitBreakerOptions func(*CircuitBreaker)
+
+func WithCircuitBreakerFailureThreshold(failure_threshold int) CircuitBreakerOptions {
+	return func(cb *CircuitBreaker) {
+		cb.failure_threshold = failure_threshold
+	}
+}
+
+func WithCircuitBreakerErrorRate(error_rate float64, window time.Duration, min_requests int) CircuitBreakerOptions {
+	return func(cb *CircuitBreaker) {
+		cb.error_rate = error_rate
+		cb.window = window
+		cb.min_requests = min_requests
+	}
+}
+
+func WithCircuitBreakerOpenTimeout(open_timeout time.Duration) CircuitBreakerOptions {
+	return func(cb *CircuitBreaker) {
+		cb.open_timeout = open_timeout
+	}
+}
+
+func WithCircuitBreakerOnStateChange(on_state_change func(from CircuitState, to CircuitState)) CircuitBreakerOptions {
+	return func(cb *CircuitBreaker) {
+		cb.on_state_change = on_state_change
+	}
+}
+
+func NewCircuitBreaker(options ...CircuitBreakerOptions) *CircuitBreaker {
+	cb := &CircuitBreaker{}
+	for _, option := range options {
+		option(cb)
+	}
+
+	if cb.failure_threshold < 1 {
+		cb.failure_threshold = DEFAULT_BREAKER_FAILURE_THRESHOLD
+	}
+
+	if cb.open_timeout <= 0 {
+		cb.open_timeout = DEFAULT_BREAKER_OPEN_TIMEOUT
+	}
+
+	if cb.error_rate > 0 && cb.min_requests < 1 {
+		cb.min_requests = DEFAULT_BREAKER_MIN_REQUESTS
+	}
+
+	return cb
+}
+
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	return cb.state
+}
+
+func (cb *CircuitBreaker) setState(state CircuitState) func() {
+	from := cb.state
+	if from == state {
+		return func() {}
+	}
+
+	cb.state = state
+	switch state {
+	case CIRCUIT_OPEN:
+		cb.opened_at = time.Now()
+	case CIRCUIT_CLOSED:
+		cb.consecutive_failures = 0
+		cb.outcomes = nil
+	}
+
+	if cb.on_state_change == nil {
+		return func() {}
+	}
+	return func() { cb.on_state_change(from, state) }
+}
+
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mutex.Lock()
+
+	notify := func() {}
+	allowed := true
+	switch cb.state {
+	case CIRCUIT_OPEN:
+		if time.Since(cb.opened_at) < cb.open_timeout {
+			allowed = false
+			break
+		}
+		notify = cb.setState(CIRCUIT_HALF_OPEN)
+		cb.probing = true
+	case CIRCUIT_HALF_OPEN:
+		if cb.probing {
+			allowed = false
+			break
+		}
+		cb.probing = true
+	}
+
+	cb.mutex.Unlock()
+	notify()
+
+	return allowed
+}
+
+func (cb *CircuitBreaker) Success() {
+	cb.mutex.Lock()
+
+	cb.probing = false
+	cb.consecutive_failures = 0
+	cb.observe(false)
+
+	notify := func() {}
+	if cb.state == CIRCUIT_HALF_OPEN {
+		notify = cb.setState(CIRCUIT_CLOSED)
+	}
+
+	cb.mutex.Unlock()
+	notify()
+}
+
+func (cb *CircuitBreaker) Failure() {
+	cb.mutex.Lock()
+
+	cb.probing = false
+	cb.consecutive_failures++
+	cb.observe(true)
+
+	notify := func() {}
+	if cb.state == CIRCUIT_HALF_OPEN || cb.tripped() {
+		notify = cb.setState(CIRCUIT_OPEN)
+	}
+
+	cb.mutex.Unlock()
+	notify()
+}
+
+func (cb *CircuitBreaker) release() {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.probing = false
+}
+
+func (cb *CircuitBreaker) record(err error) {
+	switch {
+	case err == nil:
+		cb.Success()
+	case errors.Is(err, context.Canceled):
+		cb.release()
+	case outageError(err):
+		cb.Failure()
+	default:
+		cb.Success()
+	}
+}
+
+func (cb *CircuitBreaker) observe(failed bool) {
+	if cb.error_rate <= 0 {
+		return
+	}
+
+	now := time.Now()
+	cb.outcomes = append(cb.outcomes, circuitOutcome{at: now, failed: failed})
+
+	expired := 0
+	for expired < len(cb.outcomes) && now.Sub(cb.outcomes[expired].at) > cb.window {
+		expired++
+	}
+	cb.outcomes = cb.outcomes[expired:]
+}
+
+func (cb *CircuitBreaker) tripped() bool {
+	if cb.consecutive_failures >= cb.failure_threshold {
+		return true
+	}
+
+	if cb.error_rate <= 0 || len(cb.outcomes) < cb.min_requests {
+		return false
+	}
+
+	failures := 0
+	for _, outcome := range cb.outcomes {
+		if outcome.failed {
+			failures++
+		}
+	}
+
+	return float64(failures)/float64(len(cb.outcomes)) >= cb.error_rate
+}
+
+func outageError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var status_error *DeepSeekStatusError
+	if errors.As(err, &status_error) {
+		return status_error.StatusCode >= http.StatusInternalServerError
+	}
+
+	var timeout_error *StreamTimeoutError
+	if errors.As(err, &timeout_error) {
+		return true
+	}
+
+	var net_error net.Error
+	if errors.As(err, &net_error) {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
@@ -0,0 +1,111 @@
+package deepseek_api
+
+type DeepSeekChatBuilder struct {
+	request *DeepSeekChatRequest
+
+	tool_choice_set bool
+}
+
+func NewChat(model string) *DeepSeekChatBuilder {
+	return &DeepSeekChatBuilder{request: NewDeepSeekChatRequest(nil, model)}
+}
+
+func (dcb *DeepSeekChatBuilder) Message(message DeepSeekMessage) *DeepSeekChatBuilder {
+	dcb.request.Messages = append(dcb.request.Messages, message)
+	return dcb
+}
+
+func (dcb *DeepSeekChatBuilder) System(content string) *DeepSeekChatBuilder {
+	return dcb.Message(&SystemMessage{BasicMessage: BasicMessage{Role: ROLE_SYSTEM, Content: content}})
+}
+
+func (dcb *DeepSeekChatBuilder) User(content string) *DeepSeekChatBuilder {
+	return dcb.Message(&UserMessage{BasicMessage: BasicMessage{Role: ROLE_USER, Content: content}})
+}
+
+func (dcb *DeepSeekChatBuilder) Assistant(content string) *DeepSeekChatBuilder {
+	return dcb.Message(&AssistantMessage{BasicMessage: BasicMessage{Role: ROLE_ASSISTANT, Content: content}})
+}
+
+func (dcb *DeepSeekChatBuilder) Prefix(content string) *DeepSeekChatBuilder {
+	return dcb.Message(&AssistantMessage{BasicMessage: BasicMessage{Role: ROLE_ASSISTANT, Content: content}, Prefix: true})
+}
+
+func (dcb *DeepSeekChatBuilder) ToolResult(tool_call_id string, content string) *DeepSeekChatBuilder {
+	return dcb.Message(&ToolMessage{BasicMessage: BasicMessage{Role: ROLE_TOOL, Content: content}, ToolCallId: tool_call_id})
+}
+
+func (dcb *DeepSeekChatBuilder) Temperature(temperature float64) *DeepSeekChatBuilder {
+	dcb.request.Temperature = temperature
+	return dcb
+}
+
+func (dcb *DeepSeekChatBuilder) TopP(top_p float64) *DeepSeekChatBuilder {
+	dcb.request.TopP = top_p
+	return dcb
+}
+
+func (dcb *DeepSeekChatBuilder) MaxTokens(max_tokens int64) *DeepSeekChatBuilder {
+	dcb.request.MaxTokens = max_tokens
+	return dcb
+}
+
+func (dcb *DeepSeekChatBuilder) FrequencyPenalty(frequency_penalty float64) *DeepSeekChatBuilder {
+	dcb.request.FrequencyPenalty = frequency_penalty
+	return dcb
+}
+
+func (dcb *DeepSeekChatBuilder) PresencePenalty(presence_penalty float64) *DeepSeekChatBuilder {
+	dcb.request.PresencePenalty = presence_penalty
+	return dcb
+}
+
+func (dcb *DeepSeekChatBuilder) Stop(stop ...string) *DeepSeekChatBuilder {
+	dcb.request.Stop = append(dcb.request.Stop, stop...)
+	return dcb
+}
+
+func (dcb *DeepSeekChatBuilder) JSON() *DeepSeekChatBuilder {
+	dcb.request.ResponseFormat.Type = RESPONSE_FORMAT_JSON_OBJECT
+	return dcb
+}
+
+func (dcb *DeepSeekChatBuilder) Stream(include_usage bool) *DeepSeekChatBuilder {
+	dcb.request.Stream = true
+	dcb.request.StreamOptions = &StreamOption{IncludeUsage: include_usage}
+	return dcb
+}
+
+func (dcb *DeepSeekChatBuilder) Tools(tools ...Tool) *DeepSeekChatBuilder {
+	dcb.request.Tools = append(dcb.request.Tools, tools...)
+	if len(dcb.request.Tools) > 0 && !dcb.tool_choice_set {
+		dcb.request.ToolChoice = TOOL_CHOICE_AUTO
+	}
+	return dcb
+}
+
+func (dcb *DeepSeekChatBuilder) ToolChoice(tool_choice ToolChoice) *DeepSeekChatBuilder {
+	dcb.request.ToolChoice = tool_choice
+	dcb.tool_choice_set = true
+	return dcb
+}
+
+func (dcb *DeepSeekChatBuilder) Logprobs(top_logprobs int64) *DeepSeekChatBuilder {
+	dcb.request.Logprobs = true
+	dcb.request.TopLogprobs = &top_logprobs
+	return dcb
+}
+
+func (dcb *DeepSeekChatBuilder) Build() (*DeepSeekChatRequest, error) {
+	request := *dcb.request
+	request.Messages = append([]DeepSeekMessage(nil), dcb.request.Messages...)
+	request.Stop = append([]string(nil), dcb.request.Stop...)
+	request.Tools = append([]Tool(nil), dcb.request.Tools...)
+
+	err := request.DeepSeekRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
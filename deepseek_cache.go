@@ -0,0 +1,200 @@
+package deepseek_api
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const DEFAULT_CACHE_CAPACITY = 1024
+
+type CacheStore interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte) error
+}
+
+func RequestHash(ds_req DeepSeekRequest) (string, error) {
+	if ds_req == nil {
+		return "", errors.New("request cannot be nil")
+	}
+
+	ds_req_json, err := json.Marshal(ds_req)
+	if err != nil {
+		return "", err
+	}
+
+	return requestHash(ds_req_json), nil
+}
+
+func requestHash(ds_req_json []byte) string {
+	sum := sha256.Sum256(ds_req_json)
+	return hex.EncodeToString(sum[:])
+}
+
+func (dsr *DeepSeekChatRequest) deterministic() bool {
+	return !dsr.Stream && dsr.Temperature == 0
+}
+
+func (dsr *DeepSeekCompletionsRequest) deterministic() bool {
+	return !dsr.Stream && dsr.Temperature == 0
+}
+
+func (dsc *DeepSeekClient) cacheable(ds_req DeepSeekRequest) bool {
+	if dsc.cache_store == nil || ds_req == nil || ds_req.StreamModel() {
+		return false
+	}
+
+	if dsc.cache_force {
+		return true
+	}
+
+	deterministic_req, ok := ds_req.(interface{ deterministic() bool })
+	return ok && deterministic_req.deterministic()
+}
+
+type memoryCacheEntry struct {
+	key        string
+	value      []byte
+	expires_at time.Time
+}
+
+type MemoryCacheStore struct {
+	mutex    sync.Mutex
+	entries  map[string]*list.Element
+	order    *list.List
+	capacity int
+	ttl      time.Duration
+}
+
+func NewMemoryCacheStore(capacity int, ttl time.Duration) *MemoryCacheStore {
+	if capacity < 1 {
+		capacity = DEFAULT_CACHE_CAPACITY
+	}
+
+	return &MemoryCacheStore{
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+		capacity: capacity,
+		ttl:      ttl,
+	}
+}
+
+func (mcs *MemoryCacheStore) Get(key string) ([]byte, bool, error) {
+	mcs.mutex.Lock()
+	defer mcs.mutex.Unlock()
+
+	element, ok := mcs.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*memoryCacheEntry)
+	if !entry.expires_at.IsZero() && time.Now().After(entry.expires_at) {
+		mcs.order.Remove(element)
+		delete(mcs.entries, key)
+		return nil, false, nil
+	}
+
+	mcs.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (mcs *MemoryCacheStore) Set(key string, value []byte) error {
+	mcs.mutex.Lock()
+	defer mcs.mutex.Unlock()
+
+	var expires_at time.Time
+	if mcs.ttl > 0 {
+		expires_at = time.Now().Add(mcs.ttl)
+	}
+
+	if element, ok := mcs.entries[key]; ok {
+		element.Value = &memoryCacheEntry{key: key, value: value, expires_at: expires_at}
+		mcs.order.MoveToFront(element)
+		return nil
+	}
+
+	mcs.entries[key] = mcs.order.PushFront(&memoryCacheEntry{key: key, value: value, expires_at: expires_at})
+
+	for mcs.order.Len() > mcs.capacity {
+		oldest := mcs.order.Back()
+		mcs.order.Remove(oldest)
+		delete(mcs.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+
+	return nil
+}
+
+func (mcs *MemoryCacheStore) Len() int {
+	mcs.mutex.Lock()
+	defer mcs.mutex.Unlock()
+
+	return mcs.order.Len()
+}
+
+type FileCacheStore struct {
+	dir string
+	ttl time.Duration
+}
+
+func NewFileCacheStore(dir string, ttl time.Duration) (*FileCacheStore, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileCacheStore{dir: dir, ttl: ttl}, nil
+}
+
+func (fcs *FileCacheStore) path(key string) string {
+	return filepath.Join(fcs.dir, requestHash([]byte(key))+".json")
+}
+
+func (fcs *FileCacheStore) Get(key string) ([]byte, bool, error) {
+	path := fcs.path(key)
+
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	if fcs.ttl > 0 && time.Since(info.ModTime()) > fcs.ttl {
+		os.Remove(path)
+		return nil, false, nil
+	}
+
+	value, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func (fcs *FileCacheStore) Set(key string, value []byte) error {
+	tmp, err := os.CreateTemp(fcs.dir, ".cache-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(value)
+	if close_err := tmp.Close(); err == nil {
+		err = close_err
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), fcs.path(key))
+}
@@ -0,0 +1,221 @@
+package deepseek_api
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	DEFAULT_SENTENCE_TERMINATORS     = ".!?"
+	DEFAULT_CJK_SENTENCE_TERMINATORS = "。！？…"
+	DEFAULT_SENTENCE_CLOSERS         = "\"')]}”’」』）】》"
+)
+
+type SentenceChunker struct {
+	emit func(chunk string)
+
+	terminators     string
+	cjk_terminators string
+	closers         string
+	paragraphs      bool
+	min_length      int
+
+	buffer        []rune
+	pos           int
+	in_code       bool
+	closing_fence bool
+}
+
+type SentenceChunkerOptions func(*SentenceChunker)
+
+func WithSentenceChunkerTerminators(terminators string) SentenceChunkerOptions {
+	return func(sc *SentenceChunker) {
+		sc.terminators = terminators
+	}
+}
+
+func WithSentenceChunkerCJKTerminators(cjk_terminators string) SentenceChunkerOptions {
+	return func(sc *SentenceChunker) {
+		sc.cjk_terminators = cjk_terminators
+	}
+}
+
+func WithSentenceChunkerParagraphs(paragraphs bool) SentenceChunkerOptions {
+	return func(sc *SentenceChunker) {
+		sc.paragraphs = paragraphs
+	}
+}
+
+func WithSentenceChunkerMinLength(min_length int) SentenceChunkerOptions {
+	return func(sc *SentenceChunker) {
+		sc.min_length = min_length
+	}
+}
+
+func NewSentenceChunker(emit func(chunk string), options ...SentenceChunkerOptions) *SentenceChunker {
+	sc := &SentenceChunker{
+		emit:            emit,
+		terminators:     DEFAULT_SENTENCE_TERMINATORS,
+		cjk_terminators: DEFAULT_CJK_SENTENCE_TERMINATORS,
+		closers:         DEFAULT_SENTENCE_CLOSERS,
+	}
+	for _, option := range options {
+		option(sc)
+	}
+
+	return sc
+}
+
+func (sc *SentenceChunker) Write(delta string) {
+	sc.buffer = append(sc.buffer, []rune(delta)...)
+	sc.scan()
+}
+
+func (sc *SentenceChunker) Flush() {
+	sc.cut(len(sc.buffer))
+	sc.pos = 0
+	sc.in_code = false
+	sc.closing_fence = false
+}
+
+func (sc *SentenceChunker) Wrap(handler *DeepSeekStreamHandler) *DeepSeekStreamHandler {
+	wrapped := &DeepSeekStreamHandler{}
+	if handler != nil {
+		*wrapped = *handler
+	}
+
+	wrapped.OnContent = func(delta string) {
+		if handler != nil && handler.OnContent != nil {
+			handler.OnContent(delta)
+		}
+		sc.Write(delta)
+	}
+	wrapped.OnFinish = func(finish_reason string, usage *Usage) {
+		sc.Flush()
+		if handler != nil && handler.OnFinish != nil {
+			handler.OnFinish(finish_reason, usage)
+		}
+	}
+	wrapped.OnError = func(err error) {
+		sc.Flush()
+		if handler != nil && handler.OnError != nil {
+			handler.OnError(err)
+		}
+	}
+
+	return wrapped
+}
+
+func (sc *SentenceChunker) cut(end int) {
+	chunk := strings.TrimSpace(string(sc.buffer[:end]))
+	sc.buffer = sc.buffer[end:]
+	sc.pos = 0
+
+	if chunk != "" && sc.emit != nil {
+		sc.emit(chunk)
+	}
+}
+
+func (sc *SentenceChunker) fence(pos int) (is_fence bool, ready bool) {
+	for i := 0; i < 3; i++ {
+		if pos+i >= len(sc.buffer) {
+			return false, false
+		}
+		if sc.buffer[pos+i] != '`' {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+func (sc *SentenceChunker) scan() {
+	for sc.pos < len(sc.buffer) {
+		pos := sc.pos
+
+		if pos == 0 || sc.buffer[pos-1] == '\n' {
+			is_fence, ready := sc.fence(pos)
+			if !ready {
+				return
+			}
+
+			if is_fence && !sc.in_code {
+				if strings.TrimSpace(string(sc.buffer[:pos])) != "" {
+					sc.cut(pos)
+					continue
+				}
+				sc.in_code = true
+				sc.pos = pos + 3
+				continue
+			}
+
+			if is_fence && sc.in_code {
+				sc.closing_fence = true
+				sc.pos = pos + 3
+				continue
+			}
+		}
+
+		c := sc.buffer[pos]
+		if sc.in_code {
+			if c == '\n' && sc.closing_fence {
+				sc.in_code = false
+				sc.closing_fence = false
+				sc.cut(pos + 1)
+				continue
+			}
+			sc.pos++
+			continue
+		}
+
+		end, ready := sc.boundary(pos)
+		if !ready {
+			return
+		}
+
+		if end > 0 && len([]rune(strings.TrimSpace(string(sc.buffer[:end])))) >= sc.min_length {
+			sc.cut(end)
+			continue
+		}
+
+		sc.pos++
+	}
+}
+
+func (sc *SentenceChunker) boundary(pos int) (end int, ready bool) {
+	c := sc.buffer[pos]
+
+	if c == '\n' {
+		if !sc.paragraphs {
+			return pos + 1, true
+		}
+		if pos+1 >= len(sc.buffer) {
+			return 0, false
+		}
+		if sc.buffer[pos+1] == '\n' {
+			return pos + 2, true
+		}
+		return 0, true
+	}
+
+	if sc.paragraphs {
+		return 0, true
+	}
+
+	cjk := strings.ContainsRune(sc.cjk_terminators, c)
+	if !cjk && !strings.ContainsRune(sc.terminators, c) {
+		return 0, true
+	}
+
+	end = pos + 1
+	for end < len(sc.buffer) && (strings.ContainsRune(sc.closers, sc.buffer[end]) || strings.ContainsRune(sc.terminators+sc.cjk_terminators, sc.buffer[end])) {
+		end++
+	}
+	if end >= len(sc.buffer) {
+		return 0, false
+	}
+
+	if cjk || unicode.IsSpace(sc.buffer[end]) {
+		return end, true
+	}
+	return 0, true
+}
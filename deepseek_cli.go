@@ -2,10 +2,13 @@ package deepseek_api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -15,6 +18,7 @@ const (
 	DEFAULT_HOST = "api.deepseek.com"
 
 	DEFAULT_CHAT_PATH        = "/chat/completions"
+	DEFAULT_BETA_CHAT_PATH   = "/beta/chat/completions"
 	DEFAULT_COMPLETIONS_PATH = "/beta/completions"
 	DEFAULT_MODELS_PATH      = "/models"
 	DEFAULT_BALANCE_PATH     = "/user/balance"
@@ -23,12 +27,32 @@ const (
 )
 
 type DeepSeekClient struct {
-	protocol string
-	host     string
+	protocol  string
+	host      string
+	base_path string
 
 	api_key string
 
 	http_client *http.Client
+
+	warning_handler func(warning string)
+
+	model_registry *ModelRegistry
+
+	key_pool *DeepSeekKeyPool
+
+	circuit_breaker *CircuitBreaker
+
+	hedge_stats hedgeCounters
+
+	cache_store CacheStore
+	cache_force bool
+
+	flight_group *flightGroup
+
+	stream_timeouts StreamTimeouts
+
+	continuation_max_tokens int64
 }
 
 type DeepSeekClientOptions func(*DeepSeekClient)
@@ -40,6 +64,12 @@ func WithDeepSeekClientCommunication(protocol string, host string) DeepSeekClien
 	}
 }
 
+func WithDeepSeekClientBasePath(base_path string) DeepSeekClientOptions {
+	return func(dsc *DeepSeekClient) {
+		dsc.base_path = strings.TrimSuffix(base_path, "/")
+	}
+}
+
 func WithDeepSeekClientApi(api_key string) DeepSeekClientOptions {
 	return func(dsc *DeepSeekClient) {
 		dsc.api_key = api_key
@@ -55,13 +85,62 @@ func WithDeepSeekClientHttpClient(http_client *http.Client) DeepSeekClientOption
 	}
 }
 
+func WithDeepSeekClientWarningHandler(warning_handler func(warning string)) DeepSeekClientOptions {
+	return func(dsc *DeepSeekClient) {
+		dsc.warning_handler = warning_handler
+	}
+}
+
+func WithDeepSeekClientModelRegistry(model_registry *ModelRegistry) DeepSeekClientOptions {
+	return func(dsc *DeepSeekClient) {
+		dsc.model_registry = model_registry
+	}
+}
+
+func WithDeepSeekClientKeyPool(key_pool *DeepSeekKeyPool) DeepSeekClientOptions {
+	return func(dsc *DeepSeekClient) {
+		dsc.key_pool = key_pool
+	}
+}
+
+func WithDeepSeekClientCircuitBreaker(circuit_breaker *CircuitBreaker) DeepSeekClientOptions {
+	return func(dsc *DeepSeekClient) {
+		dsc.circuit_breaker = circuit_breaker
+	}
+}
+
+func WithDeepSeekClientCache(cache_store CacheStore, force bool) DeepSeekClientOptions {
+	return func(dsc *DeepSeekClient) {
+		dsc.cache_store = cache_store
+		dsc.cache_force = force
+	}
+}
+
+func WithDeepSeekClientDeduplication(deduplication bool) DeepSeekClientOptions {
+	return func(dsc *DeepSeekClient) {
+		dsc.SetDeduplication(deduplication)
+	}
+}
+
+func WithDeepSeekClientStreamTimeouts(stream_timeouts StreamTimeouts) DeepSeekClientOptions {
+	return func(dsc *DeepSeekClient) {
+		dsc.stream_timeouts = stream_timeouts
+	}
+}
+
+func WithDeepSeekClientContinuation(max_tokens int64) DeepSeekClientOptions {
+	return func(dsc *DeepSeekClient) {
+		dsc.continuation_max_tokens = max_tokens
+	}
+}
+
 func NewDeepSeekClient(options ...DeepSeekClientOptions) *DeepSeekClient {
 	dsc := &DeepSeekClient{}
 	for _, option := range options {
 		option(dsc)
 	}
 
-	if dsc.api_key == "" {
+	if dsc.api_key == "" && dsc.key_pool == nil {
 		return nil
 	}
 
@@ -73,6 +152,10 @@ func NewDeepSeekClient(options ...DeepSeekClientOptions) *DeepSeekClient {
 		dsc.host = DEFAULT_HOST
 	}
 
+	if dsc.model_registry == nil {
+		dsc.model_registry = DefaultModelRegistry
+	}
+
 	return dsc
 }
 
@@ -104,6 +187,15 @@ func (dsc *DeepSeekClient) SetHost(host string) *DeepSeekClient {
 	return dsc
 }
 
+func (dsc *DeepSeekClient) GetBasePath() string {
+	return dsc.base_path
+}
+
+func (dsc *DeepSeekClient) SetBasePath(base_path string) *DeepSeekClient {
+	dsc.base_path = strings.TrimSuffix(base_path, "/")
+	return dsc
+}
+
 func (dsc *DeepSeekClient) GetApi() string {
 	return dsc.api_key
 }
@@ -122,56 +214,171 @@ func (dsc *DeepSeekClient) SetHttpClient(http_client *http.Client) *DeepSeekClie
 	return dsc
 }
 
+func (dsc *DeepSeekClient) GetModelRegistry() *ModelRegistry {
+	return dsc.model_registry
+}
+
+func (dsc *DeepSeekClient) SetModelRegistry(model_registry *ModelRegistry) *DeepSeekClient {
+	dsc.model_registry = model_registry
+	return dsc
+}
+
+func (dsc *DeepSeekClient) GetKeyPool() *DeepSeekKeyPool {
+	return dsc.key_pool
+}
+
+func (dsc *DeepSeekClient) SetKeyPool(key_pool *DeepSeekKeyPool) *DeepSeekClient {
+	dsc.key_pool = key_pool
+	return dsc
+}
+
+func (dsc *DeepSeekClient) GetCircuitBreaker() *CircuitBreaker {
+	return dsc.circuit_breaker
+}
+
+func (dsc *DeepSeekClient) SetCircuitBreaker(circuit_breaker *CircuitBreaker) *DeepSeekClient {
+	dsc.circuit_breaker = circuit_breaker
+	return dsc
+}
+
+func (dsc *DeepSeekClient) GetCacheStore() CacheStore {
+	return dsc.cache_store
+}
+
+func (dsc *DeepSeekClient) SetCacheStore(cache_store CacheStore, force bool) *DeepSeekClient {
+	dsc.cache_store = cache_store
+	dsc.cache_force = force
+	return dsc
+}
+
+func (dsc *DeepSeekClient) GetDeduplication() bool {
+	return dsc.flight_group != nil
+}
+
+func (dsc *DeepSeekClient) SetDeduplication(deduplication bool) *DeepSeekClient {
+	if !deduplication {
+		dsc.flight_group = nil
+	} else if dsc.flight_group == nil {
+		dsc.flight_group = newFlightGroup()
+	}
+	return dsc
+}
+
+func (dsc *DeepSeekClient) GetStreamTimeouts() StreamTimeouts {
+	return dsc.stream_timeouts
+}
+
+func (dsc *DeepSeekClient) SetStreamTimeouts(stream_timeouts StreamTimeouts) *DeepSeekClient {
+	dsc.stream_timeouts = stream_timeouts
+	return dsc
+}
+
+func (dsc *DeepSeekClient) GetContinuation() int64 {
+	return dsc.continuation_max_tokens
+}
+
+func (dsc *DeepSeekClient) SetContinuation(max_tokens int64) *DeepSeekClient {
+	dsc.continuation_max_tokens = max_tokens
+	return dsc
+}
+
 func (dsc *DeepSeekClient) getUrl(path string) string {
-	return dsc.protocol + "://" + dsc.host + path
+	return dsc.protocol + "://" + dsc.host + dsc.base_path + path
 }
 
-func (dsc *DeepSeekClient) getHeader() http.Header {
+func (dsc *DeepSeekClient) getHeader(api_key string) http.Header {
 	headers := make(http.Header)
-	headers.Set("Authorization", "Bearer "+dsc.api_key)
+	headers.Set("Authorization", "Bearer "+api_key)
 	headers.Set("Content-Type", "application/json")
 	headers.Set("Accept", "application/json")
 	return headers
 }
 
+func (dsc *DeepSeekClient) send(ctx context.Context, method string, path string, body []byte) (*http.Response, error) {
+	if dsc.circuit_breaker == nil {
+		return dsc.sendThroughKeys(ctx, method, path, body)
+	}
+
+	if !dsc.circuit_breaker.Allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := dsc.sendThroughKeys(ctx, method, path, body)
+
+	watchdog := streamWatchdogFrom(ctx)
+	if err == nil && watchdog != nil {
+		watchdog.settle(dsc.circuit_breaker)
+		return resp, nil
+	}
+	dsc.circuit_breaker.record(watchdog.error(err))
+
+	return resp, err
+}
+
+func (dsc *DeepSeekClient) sendThroughKeys(ctx context.Context, method string, path string, body []byte) (*http.Response, error) {
+	if dsc.key_pool != nil {
+		return dsc.key_pool.send(ctx, dsc, method, path, body)
+	}
+	return dsc.sendWithKey(ctx, method, path, body, dsc.api_key)
+}
+
+func (dsc *DeepSeekClient) sendWithKey(ctx context.Context, method string, path string, body []byte, api_key string) (*http.Response, error) {
+	var req_body io.Reader
+	if body != nil {
+		req_body = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, dsc.getUrl(path), req_body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = dsc.getHeader(api_key)
+
+	resp, err := dsc.httpClient(ctx).Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newDeepSeekStatusError(resp)
+	}
+
+	return resp, nil
+}
+
 func (dsc *DeepSeekClient) Do(method string, path string, ds_req DeepSeekRequest) (ds_resp DeepSeekResponse, err error) {
-	var req *http.Request
-	if ds_req == nil {
-		req, err = http.NewRequest(method, dsc.getUrl(path), nil)
-		if err != nil {
-			return nil, err
-		}
-	} else {
+	return dsc.DoContext(context.Background(), method, path, ds_req)
+}
+
+func (dsc *DeepSeekClient) DoContext(ctx context.Context, method string, path string, ds_req DeepSeekRequest) (ds_resp DeepSeekResponse, err error) {
+	var ds_req_json []byte
+	if ds_req != nil {
 		if ds_req.StreamModel() {
 			return nil, fmt.Errorf("streaming is not supported")
 		}
 
-		ds_req_json, err := json.Marshal(ds_req)
-		if err != nil {
-			return nil, err
-		}
-
-		req, err = http.NewRequest(method, dsc.getUrl(path), bytes.NewBuffer(ds_req_json))
+		ds_req_json, err = json.Marshal(ds_req)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	req.Header = dsc.getHeader()
-
-	resp, err := dsc.http_client.Do(req)
-	if err != nil {
-		return nil, err
+	var request_hash, cache_key string
+	if ds_req != nil {
+		request_hash = requestHash(ds_req_json)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP request failed with status code %d", resp.StatusCode)
+	if dsc.cacheable(ds_req) {
+		cache_key = method + " " + dsc.getUrl(path) + " " + request_hash
 	}
 
-	resp_body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	resp_body, cached := dsc.lookupCache(cache_key)
+	if !cached {
+		resp_body, err = dsc.fetch(ctx, method, path, ds_req_json, request_hash)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	dsu_resp := make(DeepSeekUniversalResponse)
@@ -205,9 +412,48 @@ func (dsc *DeepSeekClient) Do(method string, path string, ds_req DeepSeekRequest
 		return nil, err
 	}
 
+	if cache_key != "" && !cached {
+		if _, ok := ds_resp.(*DeepSeekErrorResponse); !ok {
+			dsc.cache_store.Set(cache_key, resp_body)
+		}
+	}
+
 	return ds_resp, nil
 }
 
+func (dsc *DeepSeekClient) fetch(ctx context.Context, method string, path string, body []byte, request_hash string) ([]byte, error) {
+	if dsc.flight_group == nil || request_hash == "" || ctx.Value(bypassFlightKey{}) != nil {
+		return dsc.read(ctx, method, path, body)
+	}
+
+	return dsc.flight_group.do(ctx, method+" "+path+" "+request_hash, func(ctx context.Context) ([]byte, error) {
+		return dsc.read(ctx, method, path, body)
+	})
+}
+
+func (dsc *DeepSeekClient) read(ctx context.Context, method string, path string, body []byte) ([]byte, error) {
+	resp, err := dsc.send(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+func (dsc *DeepSeekClient) lookupCache(cache_key string) ([]byte, bool) {
+	if cache_key == "" {
+		return nil, false
+	}
+
+	resp_body, ok, err := dsc.cache_store.Get(cache_key)
+	if err != nil || !ok {
+		return nil, false
+	}
+
+	return resp_body, true
+}
+
 type StreamDoEvent func(response *http.Response, args ...any) error
 
 func (dsc *DeepSeekClient) StreamDo(method string, path string, ds_req DeepSeekRequest, event StreamDoEvent, args ...any) error {
@@ -220,33 +466,98 @@ func (dsc *DeepSeekClient) StreamDo(method string, path string, ds_req DeepSeekR
 		return err
 	}
 
-	req, err := http.NewRequest(method, dsc.getUrl(path), bytes.NewBuffer(ds_req_json))
+	resp, watchdog, err := dsc.sendStream(context.Background(), method, path, ds_req_json, true)
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
+
+	err = event(resp, args...)
+	if err != nil {
+		return watchdog.error(err)
+	}
+
+	return nil
+}
+
+func (dsc *DeepSeekClient) Chat(dsc_req *DeepSeekChatRequest) (dsc_resp *DeepSeekChatResponse, err error) {
+	return dsc.ChatContext(context.Background(), dsc_req)
+}
+
+func (dsc *DeepSeekClient) ChatContext(ctx context.Context, dsc_req *DeepSeekChatRequest) (dsc_resp *DeepSeekChatResponse, err error) {
+	path := DEFAULT_CHAT_PATH
+	if dsc_req != nil && dsc_req.strictTools() {
+		path = DEFAULT_BETA_CHAT_PATH
+	}
+
+	dsc_resp, err = dsc.chat(ctx, path, dsc_req)
+	if err != nil || dsc.continuation_max_tokens <= 0 {
+		return dsc_resp, err
+	}
 
-	req.Header = dsc.getHeader()
+	return dsc.continueChat(ctx, dsc_req, dsc_resp)
+}
 
-	resp, err := dsc.http_client.Do(req)
+func (dsc *DeepSeekClient) ChatPrefix(dsc_req *DeepSeekChatRequest) (dsc_resp *DeepSeekChatResponse, err error) {
+	return dsc.ChatPrefixContext(context.Background(), dsc_req)
+}
+
+func (dsc *DeepSeekClient) ChatPrefixContext(ctx context.Context, dsc_req *DeepSeekChatRequest) (dsc_resp *DeepSeekChatResponse, err error) {
+	if dsc_req == nil {
+		return nil, errors.New("chat request cannot be nil")
+	}
+
+	prefix_message, err := dsc_req.PrefixMessage()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP request failed with status code %d", resp.StatusCode)
+	dsc_resp, err = dsc.chat(ctx, DEFAULT_BETA_CHAT_PATH, dsc_req)
+	if err != nil {
+		return nil, err
 	}
 
-	err = event(resp, args...)
+	for i := range dsc_resp.Choices {
+		dsc_resp.Choices[i].Message.Content = prefix_message.Content + dsc_resp.Choices[i].Message.Content
+	}
+
+	return dsc_resp, nil
+}
+
+func (dsc *DeepSeekClient) prepareChatRequest(dsc_req *DeepSeekChatRequest) (*DeepSeekChatRequest, error) {
+	if dsc_req == nil {
+		return nil, errors.New("chat request cannot be nil")
+	}
+
+	prepared_req := *dsc_req
+	prepared_req.Messages = stripReasoningContent(dsc_req.Messages)
+
+	err := ValidateConversation(prepared_req.Messages, prepared_req.Model)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	capability := dsc.model_registry.Capability(dsc_req.Model)
+	if errs := dsc_req.capabilityErrors(capability); len(errs) > 0 {
+		return nil, newValidationError(errs)
+	}
+
+	if dsc.warning_handler != nil {
+		for _, warning := range dsc_req.capabilityWarnings(capability) {
+			dsc.warning_handler(warning)
+		}
+	}
+
+	return &prepared_req, nil
 }
 
-func (dsc *DeepSeekClient) Chat(dsc_req *DeepSeekChatRequest) (dsc_resp *DeepSeekChatResponse, err error) {
-	ds_resp, err := dsc.Do(http.MethodPost, DEFAULT_CHAT_PATH, dsc_req)
+func (dsc *DeepSeekClient) chat(ctx context.Context, path string, dsc_req *DeepSeekChatRequest) (dsc_resp *DeepSeekChatResponse, err error) {
+	dsc_req, err = dsc.prepareChatRequest(dsc_req)
+	if err != nil {
+		return nil, err
+	}
+
+	ds_resp, err := dsc.DoContext(ctx, http.MethodPost, path, dsc_req)
 	if err != nil {
 		return nil, err
 	}
@@ -260,7 +571,11 @@ func (dsc *DeepSeekClient) Chat(dsc_req *DeepSeekChatRequest) (dsc_resp *DeepSee
 }
 
 func (dsc *DeepSeekClient) Completions(dsc_req *DeepSeekCompletionsRequest) (dsc_resp *DeepSeekCompletionsResponse, err error) {
-	ds_resp, err := dsc.Do(http.MethodPost, DEFAULT_COMPLETIONS_PATH, dsc_req)
+	return dsc.CompletionsContext(context.Background(), dsc_req)
+}
+
+func (dsc *DeepSeekClient) CompletionsContext(ctx context.Context, dsc_req *DeepSeekCompletionsRequest) (dsc_resp *DeepSeekCompletionsResponse, err error) {
+	ds_resp, err := dsc.DoContext(ctx, http.MethodPost, DEFAULT_COMPLETIONS_PATH, dsc_req)
 	if err != nil {
 		return nil, err
 	}
@@ -274,7 +589,11 @@ func (dsc *DeepSeekClient) Completions(dsc_req *DeepSeekCompletionsRequest) (dsc
 }
 
 func (dsc *DeepSeekClient) Models() (dsm_resp *DeepSeekModelsResponse, err error) {
-	ds_resp, err := dsc.Do(http.MethodGet, DEFAULT_MODELS_PATH, nil)
+	return dsc.ModelsContext(context.Background())
+}
+
+func (dsc *DeepSeekClient) ModelsContext(ctx context.Context) (dsm_resp *DeepSeekModelsResponse, err error) {
+	ds_resp, err := dsc.DoContext(ctx, http.MethodGet, DEFAULT_MODELS_PATH, nil)
 	if err != nil {
 		return nil, err
 	}
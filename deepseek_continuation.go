@@ -0,0 +1,58 @@
+package deepseek_api
+
+import (
+	"context"
+)
+
+func (dsc *DeepSeekClient) continueChat(ctx context.Context, dsc_req *DeepSeekChatRequest, dsc_resp *DeepSeekChatResponse) (*DeepSeekChatResponse, error) {
+	if len(dsc_resp.Choices) != 1 || !dsc.model_registry.Capability(dsc_req.Model).SupportsPrefix {
+		return dsc_resp, nil
+	}
+
+	choice := &dsc_resp.Choices[0]
+	usage := dsc_resp.Usage
+
+	messages := dsc_req.Messages
+	prefix_message := AssistantMessage{BasicMessage: BasicMessage{Role: ROLE_ASSISTANT}, Prefix: true}
+	if last := len(messages) - 1; last >= 0 && isPrefixMessage(messages[last]) {
+		prefix_message = *messages[last].(*AssistantMessage)
+		messages = messages[:last]
+	}
+	prefix_content := prefix_message.Content
+
+	for choice.FinishReason == FINISH_REASON_LENGTH && len(choice.Message.ToolCalls) < 1 && choice.Message.Content != "" {
+		remaining := dsc.continuation_max_tokens - usage.CompletionTokens
+		if remaining <= 0 {
+			break
+		}
+
+		next_prefix := prefix_message
+		next_prefix.Content = prefix_content + choice.Message.Content
+
+		next_req := *dsc_req
+		next_req.Messages = append(append([]DeepSeekMessage(nil), messages...), &next_prefix)
+		if next_req.MaxTokens > remaining {
+			next_req.MaxTokens = remaining
+		}
+
+		next_resp, err := dsc.chat(ctx, DEFAULT_BETA_CHAT_PATH, &next_req)
+		if err != nil {
+			dsc_resp.Usage = usage
+			return dsc_resp, err
+		}
+
+		usage.Add(next_resp.Usage)
+		if len(next_resp.Choices) < 1 {
+			break
+		}
+
+		next_choice := next_resp.Choices[0]
+		choice.Message.Content += next_choice.Message.Content
+		choice.Message.ReasoningContent += next_choice.Message.ReasoningContent
+		choice.Message.ToolCalls = next_choice.Message.ToolCalls
+		choice.FinishReason = next_choice.FinishReason
+	}
+
+	dsc_resp.Usage = usage
+	return dsc_resp, nil
+}
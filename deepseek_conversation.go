@@ -0,0 +1,118 @@
+package deepseek_api
+
+import (
+	"fmt"
+	"sort"
+)
+
+func toolCallsOf(message DeepSeekMessage) []ToolCall {
+	switch m := message.(type) {
+	case *AssistantMessage:
+		return m.ToolCalls
+	case *ResponseMessage:
+		return m.ToolCalls
+	}
+	return nil
+}
+
+func isPrefixMessage(message DeepSeekMessage) bool {
+	assistant_message, ok := message.(*AssistantMessage)
+	return ok && assistant_message.Prefix
+}
+
+func ValidateConversation(messages []DeepSeekMessage, model string) error {
+	return newValidationError(conversationErrors(messages, model))
+}
+
+func conversationErrors(messages []DeepSeekMessage, model string) []*FieldError {
+	var errs []*FieldError
+
+	pending := map[string]int{}
+	seen := map[string]bool{}
+	answered := map[string]bool{}
+
+	unanswered := func() {
+		ids := make([]string, 0, len(pending))
+		for id := range pending {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			path := fmt.Sprintf("messages[%d].tool_calls", pending[id])
+			errs = append(errs, newFieldError(path, CONSTRAINT_REFERENCE, id, fmt.Sprintf("%s: tool call %s has no matching tool message", path, id)))
+		}
+		pending = map[string]int{}
+	}
+
+	last_role := ""
+	for i, message := range messages {
+		if message == nil {
+			continue
+		}
+
+		path := fmt.Sprintf("messages[%d]", i)
+		role := message.GetRole()
+
+		if role != ROLE_TOOL && len(pending) > 0 {
+			unanswered()
+		}
+
+		switch role {
+		case ROLE_TOOL:
+			tool_call_id := ""
+			if tool_message, ok := message.(*ToolMessage); ok {
+				tool_call_id = tool_message.ToolCallId
+			}
+
+			switch {
+			case last_role != ROLE_ASSISTANT && last_role != ROLE_TOOL:
+				errs = append(errs, newFieldError(path+".role", CONSTRAINT_ORDER, role, path+" must follow an assistant message with tool_calls"))
+			case tool_call_id == "":
+				errs = append(errs, newFieldError(path+".tool_call_id", CONSTRAINT_REQUIRED, tool_call_id, path+".tool_call_id cannot be empty"))
+			case answered[tool_call_id]:
+				errs = append(errs, newFieldError(path+".tool_call_id", CONSTRAINT_UNIQUE, tool_call_id, fmt.Sprintf("%s.tool_call_id %s is answered more than once", path, tool_call_id)))
+			default:
+				if _, ok := pending[tool_call_id]; !ok {
+					errs = append(errs, newFieldError(path+".tool_call_id", CONSTRAINT_REFERENCE, tool_call_id, fmt.Sprintf("%s.tool_call_id %s does not match a preceding tool call", path, tool_call_id)))
+				}
+			}
+
+			delete(pending, tool_call_id)
+			answered[tool_call_id] = true
+		case ROLE_ASSISTANT:
+			if model == MODEL_DEEPSEEK_REASONER && last_role == ROLE_ASSISTANT {
+				errs = append(errs, newFieldError(path+".role", CONSTRAINT_ORDER, role, fmt.Sprintf("%s must not directly follow another assistant message with %s", path, model)))
+			}
+
+			for j, tool_call := range toolCallsOf(message) {
+				call_path := fmt.Sprintf("%s.tool_calls[%d].id", path, j)
+				switch {
+				case tool_call.Id == "":
+					errs = append(errs, newFieldError(call_path, CONSTRAINT_REQUIRED, tool_call.Id, call_path+" cannot be empty"))
+				case seen[tool_call.Id]:
+					errs = append(errs, newFieldError(call_path, CONSTRAINT_UNIQUE, tool_call.Id, fmt.Sprintf("%s %s is duplicated", call_path, tool_call.Id)))
+				default:
+					seen[tool_call.Id] = true
+					pending[tool_call.Id] = i
+				}
+			}
+
+			prefix := isPrefixMessage(message)
+			if prefix && i != len(messages)-1 {
+				errs = append(errs, newFieldError(path+".prefix", CONSTRAINT_PREFIX, prefix, fmt.Sprintf("%s must not be a prefix message, only the last message can be", path)))
+			}
+			if !prefix && i == len(messages)-1 {
+				errs = append(errs, newFieldError(path+".prefix", CONSTRAINT_PREFIX, prefix, path+" must not be an assistant message unless prefix is true"))
+			}
+		}
+
+		last_role = role
+	}
+
+	if len(pending) > 0 {
+		unanswered()
+	}
+
+	return errs
+}
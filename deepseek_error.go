@@ -0,0 +1,140 @@
+package deepseek_api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	CONSTRAINT_REQUIRED    = "required"
+	CONSTRAINT_RANGE       = "range"
+	CONSTRAINT_ENUM        = "enum"
+	CONSTRAINT_MAX_ITEMS   = "max_items"
+	CONSTRAINT_DEPENDENCY  = "dependency"
+	CONSTRAINT_REFERENCE   = "reference"
+	CONSTRAINT_UNSUPPORTED = "unsupported"
+	CONSTRAINT_STRICT      = "strict"
+	CONSTRAINT_PREFIX      = "prefix"
+	CONSTRAINT_INVALID     = "invalid"
+	CONSTRAINT_ORDER       = "order"
+	CONSTRAINT_UNIQUE      = "unique"
+)
+
+var ErrValidation = errors.New("validation failed")
+
+type FieldError struct {
+	Field      string
+	Constraint string
+	Value      any
+	Message    string
+}
+
+func newFieldError(field string, constraint string, value any, message string) *FieldError {
+	return &FieldError{Field: field, Constraint: constraint, Value: value, Message: message}
+}
+
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+func (e *FieldError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func (e *FieldError) nest(prefix string) *FieldError {
+	return &FieldError{Field: prefix + "." + e.Field, Constraint: e.Constraint, Value: e.Value, Message: prefix + "." + e.Message}
+}
+
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func appendUniqueFieldErrors(errs []*FieldError, more ...*FieldError) []*FieldError {
+	for _, err := range more {
+		duplicate := false
+		for _, existing := range errs {
+			if existing.Field == err.Field && existing.Constraint == err.Constraint {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func newValidationError(errs []*FieldError) error {
+	if len(errs) < 1 {
+		return nil
+	}
+	return &ValidationError{Errors: errs}
+}
+
+func (e *ValidationError) Error() string {
+	var messages []string
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	if target == ErrValidation {
+		return true
+	}
+	for _, err := range e.Errors {
+		if err == target {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *ValidationError) As(target any) bool {
+	field_error, ok := target.(**FieldError)
+	if !ok || len(e.Errors) < 1 {
+		return false
+	}
+	*field_error = e.Errors[0]
+	return true
+}
+
+func (e *ValidationError) Fields() []string {
+	fields := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		fields = append(fields, err.Field)
+	}
+	return fields
+}
+
+type DeepSeekStatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func newDeepSeekStatusError(resp *http.Response) *DeepSeekStatusError {
+	status_error := &DeepSeekStatusError{StatusCode: resp.StatusCode}
+
+	resp_body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err == nil {
+		error_resp := &DeepSeekErrorResponse{}
+		if json.Unmarshal(resp_body, error_resp) == nil {
+			status_error.Message = error_resp.Error.Message
+		}
+	}
+
+	return status_error
+}
+
+func (e *DeepSeekStatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("HTTP request failed with status code %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("HTTP request failed with status code %d", e.StatusCode)
+}
@@ -0,0 +1,149 @@
+package deepseek_api
+
+import (
+	"errors"
+	"net/http"
+)
+
+var ErrNoAvailableEndpoint = errors.New("no endpoint is available")
+
+type DeepSeekEndpoint struct {
+	Name       string
+	Protocol   string
+	Host       string
+	BasePath   string
+	ApiKey     string
+	Models     map[string]string
+	HttpClient *http.Client
+}
+
+type DeepSeekEndpointStatus struct {
+	Name  string
+	State CircuitState
+}
+
+type failoverEndpoint struct {
+	endpoint DeepSeekEndpoint
+	client   *DeepSeekClient
+	breaker  *CircuitBreaker
+}
+
+func (fe *failoverEndpoint) model(model string) string {
+	if mapped, ok := fe.endpoint.Models[model]; ok {
+		return mapped
+	}
+	return model
+}
+
+type DeepSeekFailoverClient struct {
+	endpoints []*failoverEndpoint
+
+	breaker_options []CircuitBreakerOptions
+}
+
+type DeepSeekFailoverClientOptions func(*DeepSeekFailoverClient)
+
+func WithDeepSeekFailoverBreaker(options ...CircuitBreakerOptions) DeepSeekFailoverClientOptions {
+	return func(dfc *DeepSeekFailoverClient) {
+		dfc.breaker_options = options
+	}
+}
+
+func NewDeepSeekFailoverClient(endpoints []DeepSeekEndpoint, options ...DeepSeekFailoverClientOptions) *DeepSeekFailoverClient {
+	dfc := &DeepSeekFailoverClient{}
+	for _, option := range options {
+		option(dfc)
+	}
+
+	for _, endpoint := range endpoints {
+		client := NewDeepSeekClient(
+			WithDeepSeekClientCommunication(endpoint.Protocol, endpoint.Host),
+			WithDeepSeekClientBasePath(endpoint.BasePath),
+			WithDeepSeekClientApi(endpoint.ApiKey),
+			WithDeepSeekClientHttpClient(endpoint.HttpClient),
+		)
+		if client == nil {
+			continue
+		}
+
+		dfc.endpoints = append(dfc.endpoints, &failoverEndpoint{
+			endpoint: endpoint,
+			client:   client,
+			breaker:  NewCircuitBreaker(dfc.breaker_options...),
+		})
+	}
+
+	if len(dfc.endpoints) < 1 {
+		return nil
+	}
+
+	return dfc
+}
+
+func (dfc *DeepSeekFailoverClient) Status() []DeepSeekEndpointStatus {
+	statuses := make([]DeepSeekEndpointStatus, 0, len(dfc.endpoints))
+	for _, fe := range dfc.endpoints {
+		statuses = append(statuses, DeepSeekEndpointStatus{Name: fe.endpoint.Name, State: fe.breaker.State()})
+	}
+	return statuses
+}
+
+func (dfc *DeepSeekFailoverClient) do(call func(fe *failoverEndpoint) error) error {
+	last_err := ErrNoAvailableEndpoint
+	for _, fe := range dfc.endpoints {
+		if !fe.breaker.Allow() {
+			continue
+		}
+
+		err := call(fe)
+		fe.breaker.record(err)
+		if err == nil || !outageError(err) {
+			return err
+		}
+
+		last_err = err
+	}
+
+	return last_err
+}
+
+func (dfc *DeepSeekFailoverClient) Chat(dsc_req *DeepSeekChatRequest) (dsc_resp *DeepSeekChatResponse, err error) {
+	if dsc_req == nil {
+		return nil, errors.New("chat request cannot be nil")
+	}
+
+	err = dfc.do(func(fe *failoverEndpoint) error {
+		endpoint_req := *dsc_req
+		endpoint_req.Model = fe.model(dsc_req.Model)
+
+		dsc_resp, err = fe.client.Chat(&endpoint_req)
+		return err
+	})
+
+	return dsc_resp, err
+}
+
+func (dfc *DeepSeekFailoverClient) Completions(dsc_req *DeepSeekCompletionsRequest) (dsc_resp *DeepSeekCompletionsResponse, err error) {
+	if dsc_req == nil {
+		return nil, errors.New("completions request cannot be nil")
+	}
+
+	err = dfc.do(func(fe *failoverEndpoint) error {
+		endpoint_req := *dsc_req
+		endpoint_req.Model = fe.model(dsc_req.Model)
+
+		dsc_resp, err = fe.client.Completions(&endpoint_req)
+		return err
+	})
+
+	return dsc_resp, err
+}
+
+func (dfc *DeepSeekFailoverClient) Models() (dsm_resp *DeepSeekModelsResponse, err error) {
+	err = dfc.do(func(fe *failoverEndpoint) error {
+		dsm_resp, err = fe.client.Models()
+		return err
+	})
+
+	return dsm_resp, err
+}
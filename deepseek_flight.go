@@ -0,0 +1,74 @@
+package deepseek_api
+
+import (
+	"context"
+	"sync"
+)
+
+type bypassFlightKey struct{}
+
+func withoutDeduplication(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassFlightKey{}, true)
+}
+
+type flightCall struct {
+	done      chan struct{}
+	resp_body []byte
+	err       error
+	waiters   int
+	cancel    context.CancelFunc
+}
+
+type flightGroup struct {
+	mutex sync.Mutex
+	calls map[string]*flightCall
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{calls: map[string]*flightCall{}}
+}
+
+func (fg *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	fg.mutex.Lock()
+	call, ok := fg.calls[key]
+	if !ok {
+		call_ctx, cancel := context.WithCancel(context.Background())
+		call = &flightCall{done: make(chan struct{}), cancel: cancel}
+		fg.calls[key] = call
+
+		go func() {
+			resp_body, err := fn(call_ctx)
+
+			fg.mutex.Lock()
+			call.resp_body, call.err = resp_body, err
+			fg.forget(key, call)
+			fg.mutex.Unlock()
+
+			close(call.done)
+			cancel()
+		}()
+	}
+	call.waiters++
+	fg.mutex.Unlock()
+
+	select {
+	case <-call.done:
+		return call.resp_body, call.err
+	case <-ctx.Done():
+		fg.mutex.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			fg.forget(key, call)
+		}
+		fg.mutex.Unlock()
+
+		return nil, ctx.Err()
+	}
+}
+
+func (fg *flightGroup) forget(key string, call *flightCall) {
+	if fg.calls[key] == call {
+		delete(fg.calls, key)
+	}
+}
@@ -0,0 +1,96 @@
+package deepseek_api
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+type HedgeStats struct {
+	Requests  int64
+	Hedged    int64
+	HedgeWins int64
+}
+
+type hedgeCounters struct {
+	requests   atomic.Int64
+	hedged     atomic.Int64
+	hedge_wins atomic.Int64
+}
+
+type hedgeResult struct {
+	dsc_resp *DeepSeekChatResponse
+	err      error
+	hedged   bool
+}
+
+func (dsc *DeepSeekClient) HedgeStats() HedgeStats {
+	return HedgeStats{
+		Requests:  dsc.hedge_stats.requests.Load(),
+		Hedged:    dsc.hedge_stats.hedged.Load(),
+		HedgeWins: dsc.hedge_stats.hedge_wins.Load(),
+	}
+}
+
+func (dsc *DeepSeekClient) HedgedChat(ctx context.Context, dsc_req *DeepSeekChatRequest, delay time.Duration) (dsc_resp *DeepSeekChatResponse, hedge_won bool, err error) {
+	if dsc_req == nil {
+		return nil, false, errors.New("chat request cannot be nil")
+	}
+
+	if dsc_req.Stream {
+		return nil, false, errors.New("streaming is not supported")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	launch := func(hedged bool) {
+		attempt_ctx := ctx
+		if hedged {
+			attempt_ctx = withoutDeduplication(ctx)
+		}
+
+		go func() {
+			dsc_resp, err := dsc.ChatContext(attempt_ctx, dsc_req)
+			results <- hedgeResult{dsc_resp: dsc_resp, err: err, hedged: hedged}
+		}()
+	}
+
+	dsc.hedge_stats.requests.Add(1)
+	launch(false)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	timer_c := timer.C
+	pending := 1
+	for {
+		select {
+		case <-timer_c:
+			timer_c = nil
+			dsc.hedge_stats.hedged.Add(1)
+			launch(true)
+			pending++
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				if result.hedged {
+					dsc.hedge_stats.hedge_wins.Add(1)
+				}
+				return result.dsc_resp, result.hedged, nil
+			}
+
+			if err == nil {
+				err = result.err
+			}
+
+			if pending == 0 {
+				return nil, false, err
+			}
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package deepseek_api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const DEFAULT_JSON_ATTEMPTS = 3
+
+type JSONDecodeError struct {
+	Content  string
+	Attempts int
+	Err      error
+}
+
+func (e *JSONDecodeError) Error() string {
+	return fmt.Sprintf("failed to decode json reply after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *JSONDecodeError) Unwrap() error {
+	return e.Err
+}
+
+func ExtractJSON(content string) ([]byte, error) {
+	content = strings.TrimSpace(stripCodeFence(content))
+
+	start := strings.IndexAny(content, "{[")
+	if start < 0 {
+		if json.Valid([]byte(content)) {
+			return []byte(content), nil
+		}
+		return nil, errors.New("no json object or array found in content")
+	}
+
+	for start >= 0 {
+		raw := repairJSON(scanJSON(content[start:]))
+		if json.Valid(raw) {
+			return raw, nil
+		}
+
+		next := strings.IndexAny(content[start+1:], "{[")
+		if next < 0 {
+			break
+		}
+		start += next + 1
+	}
+
+	return nil, errors.New("content does not contain valid json")
+}
+
+func stripCodeFence(content string) string {
+	start := strings.Index(content, "```")
+	if start < 0 {
+		return content
+	}
+
+	body := content[start+3:]
+	if newline := strings.IndexByte(body, '\n'); newline >= 0 {
+		body = body[newline+1:]
+	}
+
+	if end := strings.Index(body, "```"); end >= 0 {
+		body = body[:end]
+	}
+
+	return body
+}
+
+func scanJSON(content string) []byte {
+	var stack []byte
+	in_string := false
+	escaped := false
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if in_string {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				in_string = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			in_string = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			if len(stack) > 0 && stack[len(stack)-1] == c {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) == 0 {
+				return []byte(content[:i+1])
+			}
+		}
+	}
+
+	raw := []byte(content)
+	if in_string {
+		if escaped {
+			raw = raw[:len(raw)-1]
+		}
+		raw = append(raw, '"')
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		raw = append(raw, stack[i])
+	}
+
+	return raw
+}
+
+func repairJSON(raw []byte) []byte {
+	var out bytes.Buffer
+	in_string := false
+	escaped := false
+
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		if in_string {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				in_string = false
+			case c == '\n':
+				out.WriteString("\\n")
+				continue
+			}
+			out.WriteByte(c)
+			continue
+		}
+
+		switch c {
+		case '"':
+			in_string = true
+		case ',':
+			j := i + 1
+			for j < len(raw) && strings.IndexByte(" \t\r\n", raw[j]) >= 0 {
+				j++
+			}
+			if j < len(raw) && (raw[j] == '}' || raw[j] == ']') {
+				continue
+			}
+		}
+		out.WriteByte(c)
+	}
+
+	return out.Bytes()
+}
+
+func ChatJSON[T any](ctx context.Context, dsc *DeepSeekClient, dsc_req *DeepSeekChatRequest, max_attempts int) (value T, dsc_resp *DeepSeekChatResponse, err error) {
+	if dsc_req == nil {
+		return value, nil, errors.New("chat request cannot be nil")
+	}
+
+	if max_attempts < 1 {
+		max_attempts = DEFAULT_JSON_ATTEMPTS
+	}
+
+	json_req := *dsc_req
+	json_req.Messages = append([]DeepSeekMessage(nil), dsc_req.Messages...)
+	json_req.ResponseFormat.Type = RESPONSE_FORMAT_JSON_OBJECT
+
+	var decode_err error
+	var content string
+	for attempt := 1; attempt <= max_attempts; attempt++ {
+		dsc_resp, err = dsc.ChatContext(ctx, &json_req)
+		if err != nil {
+			return value, nil, err
+		}
+
+		if len(dsc_resp.Choices) < 1 {
+			return value, dsc_resp, errors.New("chat response has no choices")
+		}
+
+		content = dsc_resp.Choices[0].Message.Content
+
+		var decoded T
+		decoded, decode_err = decodeJSONContent[T](content, json_req.ResponseFormat.Schema)
+		if decode_err == nil {
+			return decoded, dsc_resp, nil
+		}
+
+		if attempt < max_attempts {
+			if content != "" {
+				json_req.Messages = append(json_req.Messages, &AssistantMessage{BasicMessage: BasicMessage{Role: ROLE_ASSISTANT, Content: content}})
+			}
+
+			retry_prompt := fmt.Sprintf("The previous reply could not be parsed as JSON: %v. Reply again with only the corrected JSON.", decode_err)
+			if _, ok := decode_err.(*SchemaValidationError); ok {
+				retry_prompt = fmt.Sprintf("The previous reply did not match the required JSON schema: %v. Reply again with only the corrected JSON.", decode_err)
+			}
+			json_req.Messages = append(json_req.Messages, &UserMessage{BasicMessage: BasicMessage{Role: ROLE_USER, Content: retry_prompt}})
+		}
+	}
+
+	return value, dsc_resp, &JSONDecodeError{Content: content, Attempts: max_attempts, Err: decode_err}
+}
+
+func decodeJSONContent[T any](content string, schema map[string]any) (value T, err error) {
+	raw, err := ExtractJSON(content)
+	if err != nil {
+		return value, err
+	}
+
+	if schema != nil {
+		var generic any
+		err = json.Unmarshal(raw, &generic)
+		if err != nil {
+			return value, err
+		}
+
+		err = ValidateJSONSchema(schema, generic)
+		if err != nil {
+			return value, err
+		}
+	}
+
+	err = json.Unmarshal(raw, &value)
+	return value, err
+}
@@ -0,0 +1,330 @@
+package deepseek_api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DEFAULT_KEY_QUARANTINE = 10 * time.Minute
+	DEFAULT_KEY_COOLDOWN   = 10 * time.Second
+)
+
+var ErrNoAvailableKey = errors.New("no api key is available")
+
+type KeyStats struct {
+	Key              string
+	InFlight         int64
+	Requests         int64
+	Failures         int64
+	RateLimited      int64
+	PaymentRequired  int64
+	Unauthorized     int64
+	CoolingUntil     time.Time
+	QuarantinedUntil time.Time
+	Balance          float64
+	Currency         string
+	BalanceUpdatedAt time.Time
+}
+
+type KeySelectionStrategy interface {
+	Select(candidates []KeyStats) int
+}
+
+type RoundRobinStrategy struct {
+	next uint64
+}
+
+func (s *RoundRobinStrategy) Select(candidates []KeyStats) int {
+	return int((atomic.AddUint64(&s.next, 1) - 1) % uint64(len(candidates)))
+}
+
+type LeastInFlightStrategy struct{}
+
+func (s LeastInFlightStrategy) Select(candidates []KeyStats) int {
+	selected := 0
+	for i, candidate := range candidates {
+		if candidate.InFlight < candidates[selected].InFlight {
+			selected = i
+		}
+	}
+	return selected
+}
+
+type MostBalanceStrategy struct{}
+
+func (s MostBalanceStrategy) Select(candidates []KeyStats) int {
+	selected := 0
+	for i, candidate := range candidates {
+		if candidate.Balance > candidates[selected].Balance {
+			selected = i
+		}
+	}
+	return selected
+}
+
+type poolKey struct {
+	api_key string
+	stats   KeyStats
+}
+
+type DeepSeekKeyPool struct {
+	mutex sync.Mutex
+	keys  []*poolKey
+
+	strategy   KeySelectionStrategy
+	quarantine time.Duration
+	cooldown   time.Duration
+}
+
+type DeepSeekKeyPoolOptions func(*DeepSeekKeyPool)
+
+func WithDeepSeekKeyPoolStrategy(strategy KeySelectionStrategy) DeepSeekKeyPoolOptions {
+	return func(dkp *DeepSeekKeyPool) {
+		dkp.strategy = strategy
+	}
+}
+
+func WithDeepSeekKeyPoolQuarantine(quarantine time.Duration) DeepSeekKeyPoolOptions {
+	return func(dkp *DeepSeekKeyPool) {
+		dkp.quarantine = quarantine
+	}
+}
+
+func WithDeepSeekKeyPoolCooldown(cooldown time.Duration) DeepSeekKeyPoolOptions {
+	return func(dkp *DeepSeekKeyPool) {
+		dkp.cooldown = cooldown
+	}
+}
+
+func NewDeepSeekKeyPool(api_keys []string, options ...DeepSeekKeyPoolOptions) *DeepSeekKeyPool {
+	dkp := &DeepSeekKeyPool{}
+	for _, api_key := range api_keys {
+		if api_key == "" {
+			continue
+		}
+		dkp.keys = append(dkp.keys, &poolKey{api_key: api_key, stats: KeyStats{Key: maskApiKey(api_key)}})
+	}
+
+	if len(dkp.keys) < 1 {
+		return nil
+	}
+
+	for _, option := range options {
+		option(dkp)
+	}
+
+	if dkp.strategy == nil {
+		dkp.strategy = &RoundRobinStrategy{}
+	}
+
+	if dkp.quarantine <= 0 {
+		dkp.quarantine = DEFAULT_KEY_QUARANTINE
+	}
+
+	if dkp.cooldown <= 0 {
+		dkp.cooldown = DEFAULT_KEY_COOLDOWN
+	}
+
+	return dkp
+}
+
+func maskApiKey(api_key string) string {
+	if len(api_key) <= 8 {
+		return "****"
+	}
+	return api_key[:3] + "..." + api_key[len(api_key)-4:]
+}
+
+func (dkp *DeepSeekKeyPool) Stats() []KeyStats {
+	dkp.mutex.Lock()
+	defer dkp.mutex.Unlock()
+
+	stats := make([]KeyStats, 0, len(dkp.keys))
+	for _, key := range dkp.keys {
+		stats = append(stats, key.stats)
+	}
+	return stats
+}
+
+func (dkp *DeepSeekKeyPool) acquire(tried map[*poolKey]bool) (*poolKey, error) {
+	dkp.mutex.Lock()
+	defer dkp.mutex.Unlock()
+
+	now := time.Now()
+
+	var candidates []*poolKey
+	var candidate_stats []KeyStats
+	for _, key := range dkp.keys {
+		if tried[key] || now.Before(key.stats.QuarantinedUntil) || now.Before(key.stats.CoolingUntil) {
+			continue
+		}
+		candidates = append(candidates, key)
+		candidate_stats = append(candidate_stats, key.stats)
+	}
+
+	if len(candidates) < 1 {
+		return nil, ErrNoAvailableKey
+	}
+
+	selected := dkp.strategy.Select(candidate_stats)
+	if selected < 0 || selected >= len(candidates) {
+		selected = 0
+	}
+
+	key := candidates[selected]
+	key.stats.InFlight++
+	key.stats.Requests++
+
+	return key, nil
+}
+
+func (dkp *DeepSeekKeyPool) release(key *poolKey, err error) {
+	dkp.mutex.Lock()
+	defer dkp.mutex.Unlock()
+
+	key.stats.InFlight--
+	dkp.record(key, err)
+}
+
+func (dkp *DeepSeekKeyPool) record(key *poolKey, err error) {
+	if err == nil {
+		return
+	}
+
+	key.stats.Failures++
+
+	var status_error *DeepSeekStatusError
+	if !errors.As(err, &status_error) {
+		return
+	}
+
+	now := time.Now()
+	switch status_error.StatusCode {
+	case http.StatusTooManyRequests:
+		key.stats.RateLimited++
+		key.stats.CoolingUntil = now.Add(dkp.cooldown)
+	case http.StatusPaymentRequired:
+		key.stats.PaymentRequired++
+		key.stats.Balance = 0
+		key.stats.CoolingUntil = now.Add(dkp.quarantine)
+	case http.StatusUnauthorized:
+		key.stats.Unauthorized++
+		key.stats.QuarantinedUntil = now.Add(dkp.quarantine)
+	}
+}
+
+func rotatableKeyError(err error) bool {
+	var status_error *DeepSeekStatusError
+	if !errors.As(err, &status_error) {
+		return false
+	}
+
+	switch status_error.StatusCode {
+	case http.StatusTooManyRequests, http.StatusPaymentRequired, http.StatusUnauthorized:
+		return true
+	}
+	return false
+}
+
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (rb *releaseBody) Close() error {
+	err := rb.ReadCloser.Close()
+	rb.once.Do(rb.release)
+	return err
+}
+
+func (dkp *DeepSeekKeyPool) send(ctx context.Context, dsc *DeepSeekClient, method string, path string, body []byte) (*http.Response, error) {
+	tried := map[*poolKey]bool{}
+
+	var last_err error
+	for {
+		key, err := dkp.acquire(tried)
+		if err != nil {
+			if last_err != nil {
+				return nil, last_err
+			}
+			return nil, err
+		}
+		tried[key] = true
+
+		resp, err := dsc.sendWithKey(ctx, method, path, body, key.api_key)
+		if err != nil {
+			dkp.release(key, err)
+			if rotatableKeyError(err) {
+				last_err = err
+				continue
+			}
+			return nil, err
+		}
+
+		resp.Body = &releaseBody{ReadCloser: resp.Body, release: func() { dkp.release(key, nil) }}
+		return resp, nil
+	}
+}
+
+func (dkp *DeepSeekKeyPool) RefreshBalances(dsc *DeepSeekClient) error {
+	var errs []string
+
+	for _, key := range dkp.keys {
+		balance, currency, err := fetchKeyBalance(dsc, key.api_key)
+
+		dkp.mutex.Lock()
+		if err != nil {
+			dkp.record(key, err)
+			errs = append(errs, key.stats.Key+": "+err.Error())
+		} else {
+			key.stats.Balance = balance
+			key.stats.Currency = currency
+			key.stats.BalanceUpdatedAt = time.Now()
+			key.stats.QuarantinedUntil = time.Time{}
+			if balance > 0 {
+				key.stats.CoolingUntil = time.Time{}
+			}
+		}
+		dkp.mutex.Unlock()
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func fetchKeyBalance(dsc *DeepSeekClient, api_key string) (float64, string, error) {
+	resp, err := dsc.sendWithKey(context.Background(), http.MethodGet, DEFAULT_BALANCE_PATH, nil, api_key)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	dsb_resp := &DeepSeekBalanceResponse{}
+	err = json.NewDecoder(resp.Body).Decode(dsb_resp)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if !dsb_resp.IsAvailable || len(dsb_resp.BalanceInfos) < 1 {
+		return 0, "", nil
+	}
+
+	balance, err := strconv.ParseFloat(dsb_resp.BalanceInfos[0].TotalBalance, 64)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return balance, dsb_resp.BalanceInfos[0].Currency, nil
+}
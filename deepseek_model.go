@@ -0,0 +1,184 @@
+package deepseek_api
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type ModelCapability struct {
+	Id               string
+	ContextWindow    int64
+	MaxOutputTokens  int64
+	DefaultMaxTokens int64
+	SupportsTools    bool
+	SupportsJSON     bool
+	SupportsPrefix   bool
+	SupportsFIM      bool
+	SupportsLogprobs bool
+	SupportsSampling bool
+}
+
+var DEFAULT_MODEL_CAPABILITY = ModelCapability{
+	ContextWindow:    128 * 1024,
+	MaxOutputTokens:  8192,
+	DefaultMaxTokens: 4096,
+	SupportsTools:    true,
+	SupportsJSON:     true,
+	SupportsPrefix:   true,
+	SupportsFIM:      true,
+	SupportsLogprobs: true,
+	SupportsSampling: true,
+}
+
+type ModelRegistry struct {
+	mutex  sync.RWMutex
+	models map[string]ModelCapability
+}
+
+func NewModelRegistry(capabilities ...ModelCapability) *ModelRegistry {
+	mr := &ModelRegistry{models: make(map[string]ModelCapability)}
+	for _, capability := range capabilities {
+		mr.Register(capability)
+	}
+	return mr
+}
+
+var DefaultModelRegistry = NewModelRegistry(
+	ModelCapability{
+		Id:               MODEL_DEEPSEEK_CHAT,
+		ContextWindow:    128 * 1024,
+		MaxOutputTokens:  8192,
+		DefaultMaxTokens: 4096,
+		SupportsTools:    true,
+		SupportsJSON:     true,
+		SupportsPrefix:   true,
+		SupportsFIM:      true,
+		SupportsLogprobs: true,
+		SupportsSampling: true,
+	},
+	ModelCapability{
+		Id:               MODEL_DEEPSEEK_REASONER,
+		ContextWindow:    128 * 1024,
+		MaxOutputTokens:  64 * 1024,
+		DefaultMaxTokens: 32 * 1024,
+		SupportsTools:    false,
+		SupportsJSON:     true,
+		SupportsPrefix:   true,
+		SupportsFIM:      false,
+		SupportsLogprobs: false,
+		SupportsSampling: false,
+	},
+)
+
+func (mr *ModelRegistry) Register(capability ModelCapability) {
+	mr.mutex.Lock()
+	defer mr.mutex.Unlock()
+
+	mr.models[capability.Id] = capability
+}
+
+func (mr *ModelRegistry) Lookup(model string) (ModelCapability, bool) {
+	mr.mutex.RLock()
+	defer mr.mutex.RUnlock()
+
+	capability, ok := mr.models[model]
+	return capability, ok
+}
+
+func (mr *ModelRegistry) Capability(model string) ModelCapability {
+	capability, ok := mr.Lookup(model)
+	if !ok {
+		capability = DEFAULT_MODEL_CAPABILITY
+		capability.Id = model
+	}
+	return capability
+}
+
+func (mr *ModelRegistry) Models() []ModelCapability {
+	mr.mutex.RLock()
+	defer mr.mutex.RUnlock()
+
+	capabilities := make([]ModelCapability, 0, len(mr.models))
+	for _, capability := range mr.models {
+		capabilities = append(capabilities, capability)
+	}
+	sort.Slice(capabilities, func(i, j int) bool {
+		return capabilities[i].Id < capabilities[j].Id
+	})
+
+	return capabilities
+}
+
+func (mr *ModelRegistry) Refresh(dsm_resp *DeepSeekModelsResponse) {
+	mr.mutex.Lock()
+	defer mr.mutex.Unlock()
+
+	for _, model := range dsm_resp.Data {
+		if _, ok := mr.models[model.Id]; ok {
+			continue
+		}
+		capability := DEFAULT_MODEL_CAPABILITY
+		capability.Id = model.Id
+		mr.models[model.Id] = capability
+	}
+}
+
+func (dsc *DeepSeekClient) RefreshModels() error {
+	dsm_resp, err := dsc.Models()
+	if err != nil {
+		return err
+	}
+
+	dsc.model_registry.Refresh(dsm_resp)
+	return nil
+}
+
+func (dsr *DeepSeekChatRequest) capabilityErrors(capability ModelCapability) []*FieldError {
+	var errs []*FieldError
+
+	if dsr.ResponseFormat.Type == RESPONSE_FORMAT_JSON_OBJECT && !capability.SupportsJSON {
+		errs = append(errs, newFieldError("response_format.type", CONSTRAINT_UNSUPPORTED, dsr.ResponseFormat.Type, fmt.Sprintf("response_format json_object is not supported by %s", capability.Id)))
+	}
+
+	if !capability.SupportsLogprobs {
+		if dsr.Logprobs {
+			errs = append(errs, newFieldError("logprobs", CONSTRAINT_UNSUPPORTED, dsr.Logprobs, fmt.Sprintf("logprobs is not supported by %s", capability.Id)))
+		}
+		if dsr.TopLogprobs != nil {
+			errs = append(errs, newFieldError("top_logprobs", CONSTRAINT_UNSUPPORTED, *dsr.TopLogprobs, fmt.Sprintf("top_logprobs is not supported by %s", capability.Id)))
+		}
+	}
+
+	if len(dsr.Tools) > 0 && !capability.SupportsTools {
+		errs = append(errs, newFieldError("tools", CONSTRAINT_UNSUPPORTED, len(dsr.Tools), fmt.Sprintf("tools are not supported by %s", capability.Id)))
+	}
+
+	return errs
+}
+
+func (dsr *DeepSeekChatRequest) capabilityWarnings(capability ModelCapability) []string {
+	var warnings []string
+
+	if capability.SupportsSampling {
+		return nil
+	}
+
+	if dsr.Temperature != 1 {
+		warnings = append(warnings, fmt.Sprintf("temperature has no effect on %s", capability.Id))
+	}
+
+	if dsr.TopP != 1 {
+		warnings = append(warnings, fmt.Sprintf("top_p has no effect on %s", capability.Id))
+	}
+
+	if dsr.FrequencyPenalty != 0 {
+		warnings = append(warnings, fmt.Sprintf("frequency_penalty has no effect on %s", capability.Id))
+	}
+
+	if dsr.PresencePenalty != 0 {
+		warnings = append(warnings, fmt.Sprintf("presence_penalty has no effect on %s", capability.Id))
+	}
+
+	return warnings
+}
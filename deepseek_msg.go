@@ -1,6 +1,9 @@
 package deepseek_api
 
-import "errors"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 type DeepSeekMessage interface {
 	DeepSeekMessage() error
@@ -24,16 +27,16 @@ type BasicMessage struct {
 
 func (m *BasicMessage) DeepSeekMessage() error {
 	if m.Role == "" {
-		return errors.New("role cannot be empty")
+		return newFieldError("role", CONSTRAINT_REQUIRED, m.Role, "role cannot be empty")
 	}
 
 	switch m.Role {
 	case ROLE_SYSTEM, ROLE_USER, ROLE_ASSISTANT, ROLE_TOOL:
 		if m.Content == "" {
-			return errors.New("content cannot be empty")
+			return newFieldError("content", CONSTRAINT_REQUIRED, m.Content, "content cannot be empty")
 		}
 	default:
-		return errors.New("role must be one of system, user, assistant, or tool")
+		return newFieldError("role", CONSTRAINT_ENUM, m.Role, "role must be one of system, user, assistant, or tool")
 	}
 
 	return nil
@@ -57,16 +60,16 @@ func (m *BasicMessage) SetContent(content string) {
 
 type SystemMessage struct {
 	BasicMessage
-	Name string `json:"name"`
+	Name string `json:"name,omitempty"`
 }
 
 func (m *SystemMessage) DeepSeekMessage() error {
 	if m.Role != ROLE_SYSTEM {
-		return errors.New("role must be system")
+		return newFieldError("role", CONSTRAINT_ENUM, m.Role, "role must be system")
 	}
 
 	if m.Content == "" {
-		return errors.New("content cannot be empty")
+		return newFieldError("content", CONSTRAINT_REQUIRED, m.Content, "content cannot be empty")
 	}
 
 	return nil
@@ -74,16 +77,16 @@ func (m *SystemMessage) DeepSeekMessage() error {
 
 type UserMessage struct {
 	BasicMessage
-	Name string `json:"name"`
+	Name string `json:"name,omitempty"`
 }
 
 func (m *UserMessage) DeepSeekMessage() error {
 	if m.Role != ROLE_USER {
-		return errors.New("role must be user")
+		return newFieldError("role", CONSTRAINT_ENUM, m.Role, "role must be user")
 	}
 
 	if m.Content == "" {
-		return errors.New("content cannot be empty")
+		return newFieldError("content", CONSTRAINT_REQUIRED, m.Content, "content cannot be empty")
 	}
 
 	return nil
@@ -91,23 +94,24 @@ func (m *UserMessage) DeepSeekMessage() error {
 
 type AssistantMessage struct {
 	BasicMessage
-	Name             string `json:"name"`
-	Prefix           bool   `json:"prefix"`
-	ReasoningContent string `json:"reasoning_content"`
+	Name             string     `json:"name,omitempty"`
+	Prefix           bool       `json:"prefix,omitempty"`
+	ReasoningContent string     `json:"reasoning_content,omitempty"`
+	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
 }
 
 func (m *AssistantMessage) DeepSeekMessage() error {
 	if m.Role != ROLE_ASSISTANT {
-		return errors.New("role must be assistant")
+		return newFieldError("role", CONSTRAINT_ENUM, m.Role, "role must be assistant")
 	}
 
-	if m.Content == "" {
-		return errors.New("content cannot be empty")
+	if m.Content == "" && len(m.ToolCalls) < 1 {
+		return newFieldError("content", CONSTRAINT_REQUIRED, m.Content, "content cannot be empty")
 	}
 
 	if m.ReasoningContent != "" {
 		if !m.Prefix {
-			return errors.New("prefix must be true if reasoning_context is not empty")
+			return newFieldError("prefix", CONSTRAINT_DEPENDENCY, m.Prefix, "prefix must be true if reasoning_context is not empty")
 		}
 	}
 
@@ -121,15 +125,15 @@ type ToolMessage struct {
 
 func (m *ToolMessage) DeepSeekMessage() error {
 	if m.Role != ROLE_TOOL {
-		return errors.New("role must be tool")
+		return newFieldError("role", CONSTRAINT_ENUM, m.Role, "role must be tool")
 	}
 
 	if m.Content == "" {
-		return errors.New("content cannot be empty")
+		return newFieldError("content", CONSTRAINT_REQUIRED, m.Content, "content cannot be empty")
 	}
 
 	if m.ToolCallId == "" {
-		return errors.New("tool_call_id cannot be empty")
+		return newFieldError("tool_call_id", CONSTRAINT_REQUIRED, m.ToolCallId, "tool_call_id cannot be empty")
 	}
 
 	return nil
@@ -146,6 +150,35 @@ type ToolCall struct {
 
 type ResponseMessage struct {
 	BasicMessage
-	ReasoningContent string     `json:"reasoning_content"`
-	ToolCalls        []ToolCall `json:"tool_calls"`
+	ReasoningContent string     `json:"reasoning_content,omitempty"`
+	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
+}
+
+func UnmarshalDeepSeekMessage(data []byte) (DeepSeekMessage, error) {
+	basic_message := BasicMessage{}
+	err := json.Unmarshal(data, &basic_message)
+	if err != nil {
+		return nil, err
+	}
+
+	var message DeepSeekMessage
+	switch basic_message.Role {
+	case ROLE_SYSTEM:
+		message = &SystemMessage{}
+	case ROLE_USER:
+		message = &UserMessage{}
+	case ROLE_ASSISTANT:
+		message = &AssistantMessage{}
+	case ROLE_TOOL:
+		message = &ToolMessage{}
+	default:
+		return nil, fmt.Errorf("unknown message role: %s", basic_message.Role)
+	}
+
+	err = json.Unmarshal(data, message)
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
 }
@@ -0,0 +1,257 @@
+package deepseek_api
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+)
+
+const (
+	partial_state_key = iota
+	partial_state_colon
+	partial_state_value
+	partial_state_comma
+)
+
+type partialFrame struct {
+	closer byte
+	state  int
+}
+
+type PartialJSONParser struct {
+	buffer []byte
+	stack  []partialFrame
+
+	started  bool
+	complete bool
+
+	in_string    bool
+	string_key   bool
+	escape_start int
+	in_scalar    bool
+
+	safe         int
+	safe_closers string
+
+	partial any
+}
+
+func NewPartialJSONParser() *PartialJSONParser {
+	return &PartialJSONParser{escape_start: -1}
+}
+
+func (pjp *PartialJSONParser) closers() string {
+	closers := make([]byte, 0, len(pjp.stack))
+	for i := len(pjp.stack) - 1; i >= 0; i-- {
+		closers = append(closers, pjp.stack[i].closer)
+	}
+	return string(closers)
+}
+
+func (pjp *PartialJSONParser) markSafe(end int) {
+	pjp.safe = end
+	pjp.safe_closers = pjp.closers()
+}
+
+func (pjp *PartialJSONParser) valueDone(end int) {
+	if len(pjp.stack) > 0 {
+		pjp.stack[len(pjp.stack)-1].state = partial_state_comma
+	}
+	pjp.markSafe(end)
+}
+
+func (pjp *PartialJSONParser) Write(delta string) any {
+	for i := 0; i < len(delta) && !pjp.complete; i++ {
+		c := delta[i]
+		if !pjp.started {
+			if c != '{' && c != '[' {
+				continue
+			}
+			pjp.started = true
+		}
+
+		pjp.buffer = append(pjp.buffer, c)
+		pjp.scan(c, len(pjp.buffer))
+	}
+
+	if partial, ok := pjp.decodePartial(); ok {
+		pjp.partial = partial
+	}
+
+	return pjp.partial
+}
+
+func (pjp *PartialJSONParser) scan(c byte, end int) {
+	if pjp.in_string {
+		switch {
+		case pjp.escape_start >= 0:
+			if pjp.buffer[pjp.escape_start+1] != 'u' || end-pjp.escape_start == 6 {
+				pjp.escape_start = -1
+			}
+		case c == '\\':
+			pjp.escape_start = end - 1
+		case c == '"':
+			pjp.in_string = false
+			if pjp.string_key {
+				pjp.stack[len(pjp.stack)-1].state = partial_state_colon
+			} else {
+				pjp.valueDone(end)
+			}
+		}
+		return
+	}
+
+	if pjp.in_scalar {
+		if !strings.ContainsRune(" \t\r\n,]}", rune(c)) {
+			return
+		}
+		pjp.in_scalar = false
+		pjp.valueDone(end - 1)
+	}
+
+	switch c {
+	case ' ', '\t', '\r', '\n':
+	case '{':
+		pjp.stack = append(pjp.stack, partialFrame{closer: '}', state: partial_state_key})
+		pjp.markSafe(end)
+	case '[':
+		pjp.stack = append(pjp.stack, partialFrame{closer: ']', state: partial_state_value})
+		pjp.markSafe(end)
+	case '}', ']':
+		if len(pjp.stack) > 0 {
+			pjp.stack = pjp.stack[:len(pjp.stack)-1]
+		}
+		pjp.valueDone(end)
+		if len(pjp.stack) == 0 {
+			pjp.complete = true
+		}
+	case '"':
+		pjp.in_string = true
+		pjp.string_key = len(pjp.stack) > 0 && pjp.stack[len(pjp.stack)-1].closer == '}' && pjp.stack[len(pjp.stack)-1].state == partial_state_key
+	case ':':
+		if len(pjp.stack) > 0 {
+			pjp.stack[len(pjp.stack)-1].state = partial_state_value
+		}
+	case ',':
+		if len(pjp.stack) > 0 {
+			frame := &pjp.stack[len(pjp.stack)-1]
+			if frame.closer == '}' {
+				frame.state = partial_state_key
+			} else {
+				frame.state = partial_state_value
+			}
+		}
+	default:
+		pjp.in_scalar = true
+	}
+}
+
+func (pjp *PartialJSONParser) decodePartial() (any, bool) {
+	if !pjp.started {
+		return nil, false
+	}
+
+	var raw []byte
+	if pjp.in_string && !pjp.string_key {
+		end := len(pjp.buffer)
+		if pjp.escape_start >= 0 {
+			end = pjp.escape_start
+		}
+		raw = append(append(append([]byte(nil), pjp.buffer[:end]...), '"'), pjp.closers()...)
+	} else {
+		raw = append(append([]byte(nil), pjp.buffer[:pjp.safe]...), pjp.safe_closers...)
+	}
+
+	var partial any
+	if json.Unmarshal(raw, &partial) != nil {
+		return nil, false
+	}
+	return partial, true
+}
+
+func (pjp *PartialJSONParser) Partial() any {
+	return pjp.partial
+}
+
+func (pjp *PartialJSONParser) Complete() bool {
+	return pjp.complete
+}
+
+func (pjp *PartialJSONParser) Raw() string {
+	return string(pjp.buffer)
+}
+
+func (pjp *PartialJSONParser) Final(value any) error {
+	if !pjp.complete {
+		return errors.New("json is incomplete")
+	}
+	return json.Unmarshal(pjp.buffer, value)
+}
+
+type PartialToolCall struct {
+	Index     int64
+	Id        string
+	Name      string
+	Arguments string
+	Partial   any
+
+	parser *PartialJSONParser
+}
+
+type ToolCallAccumulator struct {
+	calls map[int64]*PartialToolCall
+}
+
+func NewToolCallAccumulator() *ToolCallAccumulator {
+	return &ToolCallAccumulator{calls: map[int64]*PartialToolCall{}}
+}
+
+func (tca *ToolCallAccumulator) Add(deltas []ToolCallDelta) []*PartialToolCall {
+	var updated []*PartialToolCall
+	for _, delta := range deltas {
+		call, ok := tca.calls[delta.Index]
+		if !ok {
+			call = &PartialToolCall{Index: delta.Index, parser: NewPartialJSONParser()}
+			tca.calls[delta.Index] = call
+		}
+
+		if delta.Id != "" {
+			call.Id = delta.Id
+		}
+		call.Name += delta.Function.Name
+		call.Arguments += delta.Function.Arguments
+		call.Partial = call.parser.Write(delta.Function.Arguments)
+
+		updated = append(updated, call)
+	}
+	return updated
+}
+
+func (tca *ToolCallAccumulator) Calls() []*PartialToolCall {
+	calls := make([]*PartialToolCall, 0, len(tca.calls))
+	for _, call := range tca.calls {
+		calls = append(calls, call)
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Index < calls[j].Index })
+	return calls
+}
+
+func (tca *ToolCallAccumulator) ToolCalls() ([]ToolCall, error) {
+	var tool_calls []ToolCall
+	for _, call := range tca.Calls() {
+		arguments := call.Arguments
+		if strings.TrimSpace(arguments) == "" {
+			arguments = "{}"
+		}
+		if !json.Valid([]byte(arguments)) {
+			return nil, errors.New("tool call " + call.Id + " has invalid json arguments")
+		}
+
+		tool_call := ToolCall{Id: call.Id, Type: TOOL_TYPE_FUNCTION}
+		tool_call.Function.Name = call.Name
+		tool_call.Function.Arguments = arguments
+		tool_calls = append(tool_calls, tool_call)
+	}
+	return tool_calls, nil
+}
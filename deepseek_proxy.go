@@ -0,0 +1,408 @@
+package deepseek_api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	PROXY_CHAT_PATH        = "/v1/chat/completions"
+	PROXY_COMPLETIONS_PATH = "/v1/completions"
+	PROXY_MODELS_PATH      = "/v1/models"
+
+	DEFAULT_PROXY_MAX_BODY = 8 * 1024 * 1024
+)
+
+var (
+	ErrInvalidVirtualKey = errors.New("invalid api key")
+	ErrQuotaExceeded     = errors.New("tenant quota exceeded")
+)
+
+type DeepSeekProxyTenant struct {
+	Name         string `json:"name"`
+	VirtualKey   string `json:"virtual_key"`
+	RequestQuota int64  `json:"request_quota"`
+	TokenQuota   int64  `json:"token_quota"`
+}
+
+type DeepSeekProxyTenantUsage struct {
+	Requests int64
+	Usage    Usage
+}
+
+type DeepSeekProxyLogEntry struct {
+	Tenant     string
+	Method     string
+	Path       string
+	Model      string
+	Stream     bool
+	StatusCode int
+	Duration   time.Duration
+	Usage      Usage
+	Err        error
+}
+
+type proxyTenant struct {
+	tenant DeepSeekProxyTenant
+	usage  DeepSeekProxyTenantUsage
+}
+
+type DeepSeekProxy struct {
+	client *DeepSeekClient
+
+	mutex   sync.Mutex
+	tenants map[string]*proxyTenant
+
+	logger func(entry DeepSeekProxyLogEntry)
+}
+
+type DeepSeekProxyOptions func(*DeepSeekProxy)
+
+func WithDeepSeekProxyTenant(tenant DeepSeekProxyTenant) DeepSeekProxyOptions {
+	return func(dsp *DeepSeekProxy) {
+		dsp.tenants[tenant.VirtualKey] = &proxyTenant{tenant: tenant}
+	}
+}
+
+func WithDeepSeekProxyLogger(logger func(entry DeepSeekProxyLogEntry)) DeepSeekProxyOptions {
+	return func(dsp *DeepSeekProxy) {
+		dsp.logger = logger
+	}
+}
+
+func NewDeepSeekProxy(client *DeepSeekClient, options ...DeepSeekProxyOptions) *DeepSeekProxy {
+	if client == nil {
+		return nil
+	}
+
+	dsp := &DeepSeekProxy{client: client, tenants: map[string]*proxyTenant{}}
+	for _, option := range options {
+		option(dsp)
+	}
+
+	return dsp
+}
+
+func (dsp *DeepSeekProxy) TenantUsage() map[string]DeepSeekProxyTenantUsage {
+	dsp.mutex.Lock()
+	defer dsp.mutex.Unlock()
+
+	usage := make(map[string]DeepSeekProxyTenantUsage, len(dsp.tenants))
+	for _, pt := range dsp.tenants {
+		usage[pt.tenant.Name] = pt.usage
+	}
+	return usage
+}
+
+func (dsp *DeepSeekProxy) authenticate(r *http.Request) (*proxyTenant, error) {
+	if len(dsp.tenants) < 1 {
+		return nil, nil
+	}
+
+	virtual_key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	pt, ok := dsp.tenants[virtual_key]
+	if !ok || virtual_key == "" {
+		return nil, ErrInvalidVirtualKey
+	}
+
+	return pt, nil
+}
+
+func (dsp *DeepSeekProxy) reserve(pt *proxyTenant) error {
+	if pt == nil {
+		return nil
+	}
+
+	dsp.mutex.Lock()
+	defer dsp.mutex.Unlock()
+
+	if pt.tenant.RequestQuota > 0 && pt.usage.Requests >= pt.tenant.RequestQuota {
+		return ErrQuotaExceeded
+	}
+	if pt.tenant.TokenQuota > 0 && pt.usage.Usage.TotalTokens >= pt.tenant.TokenQuota {
+		return ErrQuotaExceeded
+	}
+
+	pt.usage.Requests++
+	return nil
+}
+
+func (dsp *DeepSeekProxy) record(pt *proxyTenant, usage Usage) {
+	if pt == nil {
+		return
+	}
+
+	dsp.mutex.Lock()
+	defer dsp.mutex.Unlock()
+
+	pt.usage.Usage.Add(usage)
+}
+
+func (dsp *DeepSeekProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	entry := &DeepSeekProxyLogEntry{Method: r.Method, Path: r.URL.Path}
+	defer func() {
+		entry.Duration = time.Since(start)
+		if dsp.logger != nil {
+			dsp.logger(*entry)
+		}
+	}()
+
+	pt, err := dsp.authenticate(r)
+	if err != nil {
+		entry.StatusCode, entry.Err = writeProxyError(w, http.StatusUnauthorized, err), err
+		return
+	}
+	if pt != nil {
+		entry.Tenant = pt.tenant.Name
+	}
+
+	var serve func(w http.ResponseWriter, r *http.Request, entry *DeepSeekProxyLogEntry)
+	method := http.MethodPost
+	switch r.URL.Path {
+	case PROXY_CHAT_PATH:
+		serve = dsp.serveChat
+	case PROXY_COMPLETIONS_PATH:
+		serve = dsp.serveCompletions
+	case PROXY_MODELS_PATH:
+		serve = dsp.serveModels
+		method = http.MethodGet
+	default:
+		err = fmt.Errorf("unknown path: %s", r.URL.Path)
+		entry.StatusCode, entry.Err = writeProxyError(w, http.StatusNotFound, err), err
+		return
+	}
+
+	if r.Method != method {
+		err = fmt.Errorf("method %s is not allowed", r.Method)
+		entry.StatusCode, entry.Err = writeProxyError(w, http.StatusMethodNotAllowed, err), err
+		return
+	}
+
+	err = dsp.reserve(pt)
+	if err != nil {
+		entry.StatusCode, entry.Err = writeProxyError(w, http.StatusTooManyRequests, err), err
+		return
+	}
+
+	serve(w, r, entry)
+	dsp.record(pt, entry.Usage)
+}
+
+func (dsp *DeepSeekProxy) serveChat(w http.ResponseWriter, r *http.Request, entry *DeepSeekProxyLogEntry) {
+	body, status_code, err := readProxyBody(w, r)
+	if err != nil {
+		entry.StatusCode, entry.Err = writeProxyError(w, status_code, err), err
+		return
+	}
+
+	dsc_req, err := ParseDeepSeekChatRequest(body)
+	if err != nil {
+		entry.StatusCode, entry.Err = writeProxyError(w, http.StatusBadRequest, err), err
+		return
+	}
+
+	include_usage := dsc_req.StreamOptions != nil && dsc_req.StreamOptions.IncludeUsage
+	if dsc_req.Stream {
+		dsc_req.StreamOptions = &StreamOption{IncludeUsage: true}
+	}
+
+	err = dsc_req.ValidateWithRegistry(dsp.client.GetModelRegistry())
+	if err != nil {
+		entry.StatusCode, entry.Err = writeProxyError(w, http.StatusBadRequest, err), err
+		return
+	}
+	entry.Model, entry.Stream = dsc_req.Model, dsc_req.Stream
+
+	if !dsc_req.Stream {
+		dsc_resp, err := dsp.client.ChatContext(r.Context(), dsc_req)
+		if err != nil {
+			entry.StatusCode, entry.Err = writeProxyError(w, 0, err), err
+			return
+		}
+
+		entry.Usage = dsc_resp.Usage
+		entry.StatusCode, entry.Err = writeProxyJSON(w, dsc_resp)
+		return
+	}
+
+	stream, err := dsp.client.ChatStream(r.Context(), dsc_req)
+	if err != nil {
+		entry.StatusCode, entry.Err = writeProxyError(w, 0, err), err
+		return
+	}
+	defer stream.Close()
+
+	entry.StatusCode = http.StatusOK
+	entry.Usage, entry.Err = relayProxyStream(w, stream.DeepSeekStreamReader, include_usage)
+}
+
+func (dsp *DeepSeekProxy) serveCompletions(w http.ResponseWriter, r *http.Request, entry *DeepSeekProxyLogEntry) {
+	body, status_code, err := readProxyBody(w, r)
+	if err != nil {
+		entry.StatusCode, entry.Err = writeProxyError(w, status_code, err), err
+		return
+	}
+
+	dsc_req, err := ParseDeepSeekCompletionsRequest(body)
+	if err != nil {
+		entry.StatusCode, entry.Err = writeProxyError(w, http.StatusBadRequest, err), err
+		return
+	}
+
+	include_usage := dsc_req.StreamOptions != nil && dsc_req.StreamOptions.IncludeUsage
+	if dsc_req.Stream {
+		dsc_req.StreamOptions = &StreamOption{IncludeUsage: true}
+	}
+
+	err = dsc_req.ValidateWithRegistry(dsp.client.GetModelRegistry())
+	if err != nil {
+		entry.StatusCode, entry.Err = writeProxyError(w, http.StatusBadRequest, err), err
+		return
+	}
+	entry.Model, entry.Stream = dsc_req.Model, dsc_req.Stream
+
+	if !dsc_req.Stream {
+		dsc_resp, err := dsp.client.CompletionsContext(r.Context(), dsc_req)
+		if err != nil {
+			entry.StatusCode, entry.Err = writeProxyError(w, 0, err), err
+			return
+		}
+
+		entry.Usage = dsc_resp.Usage
+		entry.StatusCode, entry.Err = writeProxyJSON(w, dsc_resp)
+		return
+	}
+
+	stream, err := dsp.client.CompletionsStream(r.Context(), dsc_req)
+	if err != nil {
+		entry.StatusCode, entry.Err = writeProxyError(w, 0, err), err
+		return
+	}
+	defer stream.Close()
+
+	entry.StatusCode = http.StatusOK
+	entry.Usage, entry.Err = relayProxyStream(w, stream, include_usage)
+}
+
+func (dsp *DeepSeekProxy) serveModels(w http.ResponseWriter, r *http.Request, entry *DeepSeekProxyLogEntry) {
+	dsm_resp, err := dsp.client.ModelsContext(r.Context())
+	if err != nil {
+		entry.StatusCode, entry.Err = writeProxyError(w, 0, err), err
+		return
+	}
+
+	entry.StatusCode, entry.Err = writeProxyJSON(w, dsm_resp)
+}
+
+func readProxyBody(w http.ResponseWriter, r *http.Request) ([]byte, int, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, DEFAULT_PROXY_MAX_BODY))
+
+	var max_bytes_error *http.MaxBytesError
+	if errors.As(err, &max_bytes_error) {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("request body must not be larger than %d bytes", max_bytes_error.Limit)
+	} else if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	return body, 0, nil
+}
+
+func relayProxyStream(w http.ResponseWriter, stream *DeepSeekStreamReader, include_usage bool) (Usage, error) {
+	rw := newRelayWriter(w, RELAY_FORMAT_SSE)
+
+	var usage Usage
+	for {
+		data, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			return usage, rw.write("", stream_done)
+		} else if err != nil {
+			error_data, _ := json.Marshal(proxyErrorBody(proxyStatusCode(err), err))
+			rw.write("", error_data)
+			return usage, err
+		}
+
+		chunk := struct {
+			Choices []json.RawMessage `json:"choices"`
+			Usage   *Usage            `json:"usage"`
+		}{}
+		if json.Unmarshal(data, &chunk) == nil && chunk.Usage != nil {
+			usage = *chunk.Usage
+			if !include_usage && len(chunk.Choices) == 0 {
+				continue
+			}
+		}
+
+		err = rw.write("", data)
+		if err != nil {
+			return usage, err
+		}
+	}
+}
+
+func proxyStatusCode(err error) int {
+	var status_error *DeepSeekStatusError
+	switch {
+	case errors.As(err, &status_error):
+		return status_error.StatusCode
+	case errors.Is(err, ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrCircuitOpen), errors.Is(err, ErrNoAvailableKey), errors.Is(err, ErrNoAvailableEndpoint):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+func proxyErrorBody(status_code int, err error) *DeepSeekErrorResponse {
+	dse_resp := &DeepSeekErrorResponse{}
+	dse_resp.Error.Message = err.Error()
+
+	var status_error *DeepSeekStatusError
+	if errors.As(err, &status_error) && status_error.Message != "" {
+		dse_resp.Error.Message = status_error.Message
+	}
+
+	switch {
+	case status_code == http.StatusBadRequest, status_code == http.StatusRequestEntityTooLarge:
+		dse_resp.Error.Type = "invalid_request_error"
+	case status_code == http.StatusUnauthorized:
+		dse_resp.Error.Type = "authentication_error"
+	case errors.Is(err, ErrQuotaExceeded):
+		dse_resp.Error.Type = "insufficient_quota"
+	case status_code == http.StatusTooManyRequests:
+		dse_resp.Error.Type = "rate_limit_error"
+	case status_code == http.StatusNotFound:
+		dse_resp.Error.Type = "not_found_error"
+	default:
+		dse_resp.Error.Type = "api_error"
+	}
+
+	return dse_resp
+}
+
+func writeProxyError(w http.ResponseWriter, status_code int, err error) int {
+	if status_code == 0 {
+		status_code = proxyStatusCode(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status_code)
+	json.NewEncoder(w).Encode(proxyErrorBody(status_code, err))
+
+	return status_code
+}
+
+func writeProxyJSON(w http.ResponseWriter, value any) (int, error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return http.StatusOK, json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,51 @@
+package deepseek_api
+
+type DeepSeekReasonerResult struct {
+	Content          string
+	ReasoningContent string
+	FinishReason     string
+	ReasoningTokens  int64
+	Usage            Usage
+}
+
+func (dsr *DeepSeekChatResponse) ReasonerResult() *DeepSeekReasonerResult {
+	result := &DeepSeekReasonerResult{Usage: dsr.Usage}
+
+	if len(dsr.Choices) > 0 {
+		result.Content = dsr.Choices[0].Message.Content
+		result.ReasoningContent = dsr.Choices[0].Message.ReasoningContent
+		result.FinishReason = dsr.Choices[0].FinishReason
+	}
+
+	if dsr.Usage.CompletionTokensDetails != nil {
+		result.ReasoningTokens = dsr.Usage.CompletionTokensDetails.ReasoningTokens
+	}
+
+	return result
+}
+
+func stripReasoningContent(messages []DeepSeekMessage) []DeepSeekMessage {
+	stripped := make([]DeepSeekMessage, len(messages))
+	copy(stripped, messages)
+
+	for i, message := range messages {
+		switch m := message.(type) {
+		case *AssistantMessage:
+			if m.ReasoningContent == "" || (m.Prefix && i == len(messages)-1) {
+				continue
+			}
+			assistant_message := *m
+			assistant_message.ReasoningContent = ""
+			stripped[i] = &assistant_message
+		case *ResponseMessage:
+			if m.ReasoningContent == "" {
+				continue
+			}
+			response_message := *m
+			response_message.ReasoningContent = ""
+			stripped[i] = &response_message
+		}
+	}
+
+	return stripped
+}
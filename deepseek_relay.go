@@ -0,0 +1,140 @@
+package deepseek_api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	RELAY_FORMAT_SSE    = "sse"
+	RELAY_FORMAT_NDJSON = "ndjson"
+
+	OBJECT_CHAT_COMPLETION_DONE = "chat.completion.done"
+)
+
+type DeepSeekRelayDone struct {
+	Object       string `json:"object"`
+	FinishReason string `json:"finish_reason"`
+	Usage        *Usage `json:"usage,omitempty"`
+}
+
+type DeepSeekRelayResult struct {
+	Chunks       int
+	FinishReason string
+	Usage        Usage
+}
+
+type relayWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	format  string
+}
+
+func newRelayWriter(w http.ResponseWriter, format string) *relayWriter {
+	flusher, _ := w.(http.Flusher)
+
+	if format == RELAY_FORMAT_NDJSON {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	} else {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Connection", "keep-alive")
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	return &relayWriter{w: w, flusher: flusher, format: format}
+}
+
+func (rw *relayWriter) write(event string, data []byte) (err error) {
+	if rw.format == RELAY_FORMAT_NDJSON {
+		_, err = fmt.Fprintf(rw.w, "%s\n", data)
+	} else if event != "" {
+		_, err = fmt.Fprintf(rw.w, "event: %s\ndata: %s\n\n", event, data)
+	} else {
+		_, err = fmt.Fprintf(rw.w, "data: %s\n\n", data)
+	}
+
+	if err == nil && rw.flusher != nil {
+		rw.flusher.Flush()
+	}
+	return err
+}
+
+func (rw *relayWriter) writeError(err error) error {
+	error_data, _ := json.Marshal(proxyErrorBody(proxyStatusCode(err), err))
+	return rw.write("error", error_data)
+}
+
+func (dsc *DeepSeekClient) RelayChatStream(ctx context.Context, w http.ResponseWriter, dsc_req *DeepSeekChatRequest, format string) (*DeepSeekRelayResult, error) {
+	if dsc_req == nil {
+		return nil, errors.New("chat request cannot be nil")
+	}
+
+	if format != RELAY_FORMAT_SSE && format != RELAY_FORMAT_NDJSON {
+		return nil, fmt.Errorf("unknown relay format: %s", format)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream_req := *dsc_req
+	stream_req.Stream = true
+	stream_req.StreamOptions = &StreamOption{IncludeUsage: true}
+
+	stream, err := dsc.ChatStream(ctx, &stream_req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	rw := newRelayWriter(w, format)
+	result := &DeepSeekRelayResult{}
+	for {
+		data, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			rw.writeError(err)
+			return result, err
+		}
+
+		chunk, err := decodeChatChunk(data)
+		if err != nil {
+			rw.writeError(err)
+			return result, err
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.FinishReason != nil {
+				result.FinishReason = *choice.FinishReason
+			}
+		}
+		if chunk.Usage != nil {
+			result.Usage = *chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		result.Chunks++
+		err = rw.write("", data)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	done_data, err := json.Marshal(DeepSeekRelayDone{Object: OBJECT_CHAT_COMPLETION_DONE, FinishReason: result.FinishReason, Usage: &result.Usage})
+	if err != nil {
+		return result, err
+	}
+
+	return result, rw.write("done", done_data)
+}
@@ -1,8 +1,9 @@
 package deepseek_api
 
 import (
+	"encoding/json"
 	"errors"
-	"strings"
+	"fmt"
 )
 
 const (
@@ -16,7 +17,8 @@ type DeepSeekRequest interface {
 }
 
 type ResponseFormat struct {
-	Type string `json:"type"`
+	Type   string         `json:"type"`
+	Schema map[string]any `json:"-"`
 }
 
 type StreamOption struct {
@@ -29,6 +31,7 @@ type Tool struct {
 		Description string         `json:"description"`
 		Name        string         `json:"name"`
 		Parameters  map[string]any `json:"parameters"`
+		Strict      bool           `json:"strict,omitempty"`
 	} `json:"function"`
 }
 
@@ -48,15 +51,48 @@ type DeepSeekChatRequest struct {
 	MaxTokens        int64             `json:"max_tokens"`
 	PresencePenalty  float64           `json:"presence_penalty"`
 	ResponseFormat   ResponseFormat    `json:"response_format"`
-	Stop             []string          `json:"stop"`
-	Stream           bool              `json:"stream"`
-	StreamOptions    *StreamOption     `json:"stream_options"`
+	Stop             []string          `json:"stop,omitempty"`
+	Stream           bool              `json:"stream,omitempty"`
+	StreamOptions    *StreamOption     `json:"stream_options,omitempty"`
 	Temperature      float64           `json:"temperature"`
 	TopP             float64           `json:"top_p"`
-	Tools            []Tool            `json:"tools"`
-	ToolChoice       string            `json:"tool_choice"`
-	Logprobs         bool              `json:"logprobs"`
-	TopLogprobs      *int64            `json:"top_logprobs"`
+	Tools            []Tool            `json:"tools,omitempty"`
+	ToolChoice       ToolChoice        `json:"tool_choice,omitempty"`
+	Logprobs         bool              `json:"logprobs,omitempty"`
+	TopLogprobs      *int64            `json:"top_logprobs,omitempty"`
+}
+
+type deepSeekChatRequestJSON DeepSeekChatRequest
+
+func (dsr *DeepSeekChatRequest) MarshalJSON() ([]byte, error) {
+	dsr_json := deepSeekChatRequestJSON(*dsr)
+	if len(dsr_json.Tools) < 1 {
+		dsr_json.ToolChoice = ""
+	}
+	return json.Marshal(&dsr_json)
+}
+
+func (dsr *DeepSeekChatRequest) UnmarshalJSON(data []byte) error {
+	dsr_json := struct {
+		*deepSeekChatRequestJSON
+		Messages []json.RawMessage `json:"messages"`
+	}{deepSeekChatRequestJSON: (*deepSeekChatRequestJSON)(dsr)}
+
+	err := json.Unmarshal(data, &dsr_json)
+	if err != nil {
+		return err
+	}
+
+	dsr.Messages = make([]DeepSeekMessage, 0, len(dsr_json.Messages))
+	for i, raw_message := range dsr_json.Messages {
+		message, err := UnmarshalDeepSeekMessage(raw_message)
+		if err != nil {
+			return fmt.Errorf("messages[%d]: %w", i, err)
+		}
+		dsr.Messages = append(dsr.Messages, message)
+	}
+
+	return nil
 }
 
 func NewDeepSeekChatRequest(messages []DeepSeekMessage, model string) *DeepSeekChatRequest {
@@ -64,7 +100,7 @@ func NewDeepSeekChatRequest(messages []DeepSeekMessage, model string) *DeepSeekC
 		Messages:         messages,
 		Model:            model,
 		FrequencyPenalty: 0,
-		MaxTokens:        4096,
+		MaxTokens:        DefaultModelRegistry.Capability(model).DefaultMaxTokens,
 		PresencePenalty:  0,
 		ResponseFormat: ResponseFormat{
 			Type: RESPONSE_FORMAT_TEXT,
@@ -81,89 +117,165 @@ func NewDeepSeekChatRequest(messages []DeepSeekMessage, model string) *DeepSeekC
 	}
 }
 
-type multiError []error
+func ParseDeepSeekChatRequest(data []byte) (*DeepSeekChatRequest, error) {
+	dsc_req := NewDeepSeekChatRequest(nil, "")
+	dsc_req.MaxTokens = 0
+	dsc_req.ToolChoice = ""
+
+	err := json.Unmarshal(data, dsc_req)
+	if err != nil {
+		return nil, err
+	}
 
-func (m multiError) Error() string {
-	var errStrings []string
-	for _, err := range m {
-		errStrings = append(errStrings, err.Error())
+	if dsc_req.MaxTokens == 0 {
+		dsc_req.MaxTokens = DefaultModelRegistry.Capability(dsc_req.Model).DefaultMaxTokens
 	}
-	return strings.Join(errStrings, "; ")
+
+	if dsc_req.ToolChoice == "" {
+		dsc_req.ToolChoice = TOOL_CHOICE_NONE
+		if len(dsc_req.Tools) > 0 {
+			dsc_req.ToolChoice = TOOL_CHOICE_AUTO
+		}
+	}
+
+	return dsc_req, nil
 }
 
 func (dsr *DeepSeekChatRequest) DeepSeekRequest() error {
-	var errs []error
+	return dsr.ValidateWithRegistry(DefaultModelRegistry)
+}
+
+func (dsr *DeepSeekChatRequest) ValidateWithRegistry(registry *ModelRegistry) error {
+	var errs []*FieldError
+
+	capability := registry.Capability(dsr.Model)
 
 	if len(dsr.Messages) < 1 {
-		errs = append(errs, errors.New("messages must be at least one"))
+		errs = append(errs, newFieldError("messages", CONSTRAINT_REQUIRED, len(dsr.Messages), "messages must be at least one"))
+	}
+
+	for i, message := range dsr.Messages {
+		path := fmt.Sprintf("messages[%d]", i)
+		if message == nil {
+			errs = append(errs, newFieldError(path, CONSTRAINT_REQUIRED, nil, path+" must be set"))
+			continue
+		}
+
+		err := message.DeepSeekMessage()
+		if err == nil {
+			continue
+		}
+
+		var field_error *FieldError
+		if errors.As(err, &field_error) {
+			errs = append(errs, field_error.nest(path))
+		} else {
+			errs = append(errs, newFieldError(path, CONSTRAINT_INVALID, message, path+": "+err.Error()))
+		}
 	}
 
+	errs = appendUniqueFieldErrors(errs, conversationErrors(dsr.Messages, dsr.Model)...)
+
 	if dsr.Model == "" {
-		errs = append(errs, errors.New("model must be set"))
+		errs = append(errs, newFieldError("model", CONSTRAINT_REQUIRED, dsr.Model, "model must be set"))
 	}
 
 	if dsr.FrequencyPenalty < -2 || dsr.FrequencyPenalty > 2 {
-		errs = append(errs, errors.New("frequency_penalty must be between -2 and 2"))
+		errs = append(errs, newFieldError("frequency_penalty", CONSTRAINT_RANGE, dsr.FrequencyPenalty, "frequency_penalty must be between -2 and 2"))
 	}
 
-	if dsr.MaxTokens < 1 || dsr.MaxTokens > 8192 {
-		errs = append(errs, errors.New("max_tokens must be between 1 and 8192"))
+	if dsr.MaxTokens < 1 || dsr.MaxTokens > capability.MaxOutputTokens {
+		errs = append(errs, newFieldError("max_tokens", CONSTRAINT_RANGE, dsr.MaxTokens, fmt.Sprintf("max_tokens must be between 1 and %d", capability.MaxOutputTokens)))
 	}
 
 	if dsr.PresencePenalty < -2 || dsr.PresencePenalty > 2 {
-		errs = append(errs, errors.New("presence_penalty must be between -2 and 2"))
+		errs = append(errs, newFieldError("presence_penalty", CONSTRAINT_RANGE, dsr.PresencePenalty, "presence_penalty must be between -2 and 2"))
 	}
 
 	if dsr.ResponseFormat.Type != RESPONSE_FORMAT_TEXT && dsr.ResponseFormat.Type != RESPONSE_FORMAT_JSON_OBJECT {
-		errs = append(errs, errors.New("response_format.type must be text or json_object"))
+		errs = append(errs, newFieldError("response_format.type", CONSTRAINT_ENUM, dsr.ResponseFormat.Type, "response_format.type must be text or json_object"))
 	}
 
 	if len(dsr.Stop) > 16 {
-		errs = append(errs, errors.New("stop must be less than 16 lists"))
+		errs = append(errs, newFieldError("stop", CONSTRAINT_MAX_ITEMS, len(dsr.Stop), "stop must be less than 16 lists"))
 	}
 
 	if dsr.Stream {
 		if dsr.StreamOptions == nil {
-			errs = append(errs, errors.New("stream_options must be set when stream is true"))
+			errs = append(errs, newFieldError("stream_options", CONSTRAINT_DEPENDENCY, dsr.StreamOptions, "stream_options must be set when stream is true"))
 		}
 	} else {
 		if dsr.StreamOptions != nil {
-			errs = append(errs, errors.New("stream_options must be nil when stream is false"))
+			errs = append(errs, newFieldError("stream_options", CONSTRAINT_DEPENDENCY, dsr.StreamOptions, "stream_options must be nil when stream is false"))
 		}
 	}
 
 	if dsr.Temperature < 0 || dsr.Temperature > 2 {
-		errs = append(errs, errors.New("temperature must be between 0 and 2"))
+		errs = append(errs, newFieldError("temperature", CONSTRAINT_RANGE, dsr.Temperature, "temperature must be between 0 and 2"))
 	}
 
 	if dsr.TopP < 0 || dsr.TopP > 1 {
-		errs = append(errs, errors.New("top_p must be between 0 and 1"))
+		errs = append(errs, newFieldError("top_p", CONSTRAINT_RANGE, dsr.TopP, "top_p must be between 0 and 1"))
 	}
 
 	switch dsr.ToolChoice {
 	case TOOL_CHOICE_NONE, TOOL_CHOICE_AUTO:
 	case TOOL_CHOICE_REQUIRED:
 		if len(dsr.Tools) < 1 {
-			errs = append(errs, errors.New("tools must be defined when tool_choice is required"))
+			errs = append(errs, newFieldError("tools", CONSTRAINT_DEPENDENCY, len(dsr.Tools), "tools must be defined when tool_choice is required"))
 		}
 	default:
-		errs = append(errs, errors.New("tool_choice must be one of none, auto, or required"))
+		if name := dsr.ToolChoice.FunctionName(); name == "" {
+			errs = append(errs, newFieldError("tool_choice", CONSTRAINT_ENUM, dsr.ToolChoice, "tool_choice must be one of none, auto, or required"))
+		} else if !dsr.hasTool(name) {
+			errs = append(errs, newFieldError("tool_choice", CONSTRAINT_REFERENCE, name, fmt.Sprintf("tool_choice function %s must be defined in tools", name)))
+		}
 	}
 
+	errs = append(errs, dsr.toolErrors()...)
+
+	errs = append(errs, dsr.capabilityErrors(capability)...)
+
 	if dsr.TopLogprobs != nil {
 		if !dsr.Logprobs {
-			errs = append(errs, errors.New("top_logprobs must be defined when logprobs is true"))
+			errs = append(errs, newFieldError("top_logprobs", CONSTRAINT_DEPENDENCY, *dsr.TopLogprobs, "top_logprobs must be defined when logprobs is true"))
 		}
 		if *dsr.TopLogprobs < 0 || *dsr.TopLogprobs > 20 {
-			errs = append(errs, errors.New("top_logprobs must be between 0 and 20"))
+			errs = append(errs, newFieldError("top_logprobs", CONSTRAINT_RANGE, *dsr.TopLogprobs, "top_logprobs must be between 0 and 20"))
 		}
 	}
 
-	if len(errs) > 0 {
-		return multiError(errs)
+	return newValidationError(errs)
+}
+
+func (dsr *DeepSeekChatRequest) PrefixMessage() (*AssistantMessage, error) {
+	var errs []*FieldError
+
+	if len(dsr.Messages) < 1 {
+		return nil, newValidationError([]*FieldError{newFieldError("messages", CONSTRAINT_REQUIRED, len(dsr.Messages), "messages must be at least one")})
+	}
+
+	for i, message := range dsr.Messages[:len(dsr.Messages)-1] {
+		if assistant_message, ok := message.(*AssistantMessage); ok && assistant_message.Prefix {
+			errs = append(errs, newFieldError(fmt.Sprintf("messages[%d].prefix", i), CONSTRAINT_PREFIX, true, fmt.Sprintf("messages[%d] must not be a prefix message, only the last message can be", i)))
+		}
+	}
+
+	last := len(dsr.Messages) - 1
+	prefix_message, ok := dsr.Messages[last].(*AssistantMessage)
+	if !ok || !prefix_message.Prefix {
+		errs = append(errs, newFieldError(fmt.Sprintf("messages[%d].prefix", last), CONSTRAINT_PREFIX, false, "last message must be an assistant message with prefix set to true"))
+	} else if err := prefix_message.DeepSeekMessage(); err != nil {
+		var field_error *FieldError
+		if errors.As(err, &field_error) {
+			errs = append(errs, field_error.nest(fmt.Sprintf("messages[%d]", last)))
+		}
 	}
-	return nil
 
+	if len(errs) > 0 {
+		return nil, newValidationError(errs)
+	}
+	return prefix_message, nil
 }
 
 func (dsr *DeepSeekChatRequest) StreamModel() bool {
@@ -173,15 +285,15 @@ func (dsr *DeepSeekChatRequest) StreamModel() bool {
 type DeepSeekCompletionsRequest struct {
 	Model            string        `json:"model"`
 	Prompt           string        `json:"prompt"`
-	Echo             bool          `json:"echo"`
+	Echo             bool          `json:"echo,omitempty"`
 	FrequencyPenalty float64       `json:"frequency_penalty"`
 	Logprobs         int64         `json:"logprobs"`
 	MaxTokens        int64         `json:"max_tokens"`
 	PresencePenalty  float64       `json:"presence_penalty"`
-	Stop             []string      `json:"stop"`
-	Stream           bool          `json:"stream"`
-	StreamOptions    *StreamOption `json:"stream_options"`
-	Suffix           *string       `json:"suffix"`
+	Stop             []string      `json:"stop,omitempty"`
+	Stream           bool          `json:"stream,omitempty"`
+	StreamOptions    *StreamOption `json:"stream_options,omitempty"`
+	Suffix           *string       `json:"suffix,omitempty"`
 	Temperature      float64       `json:"temperature"`
 	TopP             float64       `json:"top_p"`
 }
@@ -204,63 +316,73 @@ func NewDeepSeekCompletionsRequest(model string, prompt string) *DeepSeekComplet
 	}
 }
 
+func ParseDeepSeekCompletionsRequest(data []byte) (*DeepSeekCompletionsRequest, error) {
+	dsc_req := NewDeepSeekCompletionsRequest("", "")
+
+	err := json.Unmarshal(data, dsc_req)
+	if err != nil {
+		return nil, err
+	}
+
+	return dsc_req, nil
+}
+
 func (dsr *DeepSeekCompletionsRequest) DeepSeekRequest() error {
-	// var err error
-	var errs []error
+	return dsr.ValidateWithRegistry(DefaultModelRegistry)
+}
+
+func (dsr *DeepSeekCompletionsRequest) ValidateWithRegistry(registry *ModelRegistry) error {
+	var errs []*FieldError
 
 	if dsr.Model == "" {
-		// errs = append(errs, errors.New("model must be set"))
-		errs = append(errs, errors.New("model must be set"))
+		errs = append(errs, newFieldError("model", CONSTRAINT_REQUIRED, dsr.Model, "model must be set"))
+	} else if capability := registry.Capability(dsr.Model); !capability.SupportsFIM {
+		errs = append(errs, newFieldError("model", CONSTRAINT_UNSUPPORTED, dsr.Model, fmt.Sprintf("fim completion is not supported by %s", capability.Id)))
 	}
 
 	if dsr.Prompt == "" {
-		// errs = append(errs, errors.New("prompt must be set"))
-		errs = append(errs, errors.New("prompt must be set"))
+		errs = append(errs, newFieldError("prompt", CONSTRAINT_REQUIRED, dsr.Prompt, "prompt must be set"))
 	}
 
 	if dsr.FrequencyPenalty < -2 || dsr.FrequencyPenalty > 2 {
-		// errs = append(errs, errors.New("frequency_penalty must be between -2 and 2"))
-		errs = append(errs, errors.New("frequency_penalty must be between -2 and 2"))
+		errs = append(errs, newFieldError("frequency_penalty", CONSTRAINT_RANGE, dsr.FrequencyPenalty, "frequency_penalty must be between -2 and 2"))
 	}
 
 	if dsr.Logprobs < 0 || dsr.Logprobs > 20 {
-		errs = append(errs, errors.New("logprobs must be between 0 and 20"))
+		errs = append(errs, newFieldError("logprobs", CONSTRAINT_RANGE, dsr.Logprobs, "logprobs must be between 0 and 20"))
 	}
 
 	if dsr.MaxTokens < 1 {
-		errs = append(errs, errors.New("max_tokens must be greater than 0"))
+		errs = append(errs, newFieldError("max_tokens", CONSTRAINT_RANGE, dsr.MaxTokens, "max_tokens must be greater than 0"))
 	}
 
 	if dsr.PresencePenalty < -2 || dsr.PresencePenalty > 2 {
-		errs = append(errs, errors.New("presence_penalty must be between -2 and 2"))
+		errs = append(errs, newFieldError("presence_penalty", CONSTRAINT_RANGE, dsr.PresencePenalty, "presence_penalty must be between -2 and 2"))
 	}
 
 	if len(dsr.Stop) > 16 {
-		errs = append(errs, errors.New("stop must be less than 16 lists"))
+		errs = append(errs, newFieldError("stop", CONSTRAINT_MAX_ITEMS, len(dsr.Stop), "stop must be less than 16 lists"))
 	}
 
 	if dsr.Stream {
 		if dsr.StreamOptions == nil {
-			errs = append(errs, errors.New("stream_options must be set when stream is true"))
+			errs = append(errs, newFieldError("stream_options", CONSTRAINT_DEPENDENCY, dsr.StreamOptions, "stream_options must be set when stream is true"))
 		}
 	} else {
 		if dsr.StreamOptions != nil {
-			errs = append(errs, errors.New("stream_options must be nil when stream is false"))
+			errs = append(errs, newFieldError("stream_options", CONSTRAINT_DEPENDENCY, dsr.StreamOptions, "stream_options must be nil when stream is false"))
 		}
 	}
 
 	if dsr.Temperature < 0 || dsr.Temperature > 2 {
-		errs = append(errs, errors.New("temperature must be between 0 and 2"))
+		errs = append(errs, newFieldError("temperature", CONSTRAINT_RANGE, dsr.Temperature, "temperature must be between 0 and 2"))
 	}
 
 	if dsr.TopP < 0 || dsr.TopP > 1 {
-		errs = append(errs, errors.New("top_p must be between 0 and 1"))
+		errs = append(errs, newFieldError("top_p", CONSTRAINT_RANGE, dsr.TopP, "top_p must be between 0 and 1"))
 	}
 
-	if len(errs) > 0 {
-		return multiError(errs)
-	}
-	return nil
+	return newValidationError(errs)
 }
 
 func (dsr *DeepSeekCompletionsRequest) StreamModel() bool {
@@ -61,12 +61,49 @@ type Usage struct {
 	} `json:"completion_tokens_details"`
 }
 
+func (u *Usage) Add(usage Usage) {
+	u.CompletionTokens += usage.CompletionTokens
+	u.PromptTokens += usage.PromptTokens
+	u.TotalTokens += usage.TotalTokens
+
+	if usage.PromptCacheHitTokens != nil {
+		if u.PromptCacheHitTokens == nil {
+			u.PromptCacheHitTokens = new(int64)
+		}
+		*u.PromptCacheHitTokens += *usage.PromptCacheHitTokens
+	}
+
+	if usage.PromptCacheMissTokens != nil {
+		if u.PromptCacheMissTokens == nil {
+			u.PromptCacheMissTokens = new(int64)
+		}
+		*u.PromptCacheMissTokens += *usage.PromptCacheMissTokens
+	}
+
+	if usage.CompletionTokensDetails != nil {
+		if u.CompletionTokensDetails == nil {
+			u.CompletionTokensDetails = &struct {
+				ReasoningTokens int64 `json:"reasoning_tokens"`
+			}{}
+		}
+		u.CompletionTokensDetails.ReasoningTokens += usage.CompletionTokensDetails.ReasoningTokens
+	}
+}
+
 const (
 	OBJECT_CHAT_COMPLETION = "chat.completion"
 	OBJECT_TEXT_COMPLETION = "text_completion"
 	OBJECT_LIST            = "list"
 )
 
+const (
+	FINISH_REASON_STOP                         = "stop"
+	FINISH_REASON_LENGTH                       = "length"
+	FINISH_REASON_CONTENT_FILTER               = "content_filter"
+	FINISH_REASON_TOOL_CALLS                   = "tool_calls"
+	FINISH_REASON_INSUFFICIENT_SYSTEM_RESOURCE = "insufficient_system_resource"
+)
+
 type DeepSeekChatResponse struct {
 	Id                string       `json:"id"`
 	Choices           []ChatChoice `json:"choices"`
@@ -127,7 +164,7 @@ type DeepSeekBalanceResponse struct {
 		TotalBalance    string `json:"total_balance"`
 		GrantedBalance  string `json:"granted_balance"`
 		ToppedUpBalance string `json:"topped_up_balance"`
-	}
+	} `json:"balance_infos"`
 }
 
 func (dsr *DeepSeekBalanceResponse) DeepSeekResponse() error {
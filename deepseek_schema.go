@@ -0,0 +1,431 @@
+package deepseek_api
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	SCHEMA_TYPE_OBJECT  = "object"
+	SCHEMA_TYPE_ARRAY   = "array"
+	SCHEMA_TYPE_STRING  = "string"
+	SCHEMA_TYPE_NUMBER  = "number"
+	SCHEMA_TYPE_INTEGER = "integer"
+	SCHEMA_TYPE_BOOLEAN = "boolean"
+	SCHEMA_TYPE_NULL    = "null"
+)
+
+type SchemaViolation struct {
+	Path       string
+	Constraint string
+	Message    string
+}
+
+func (v SchemaViolation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+type SchemaValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaValidationError) Error() string {
+	var violations []string
+	for _, violation := range e.Violations {
+		violations = append(violations, violation.String())
+	}
+	return "json schema validation failed: " + strings.Join(violations, "; ")
+}
+
+func JSONSchemaOf[T any]() map[string]any {
+	var value T
+	return schemaOfType(reflect.TypeOf(&value).Elem(), map[reflect.Type]bool{})
+}
+
+func schemaOfType(t reflect.Type, visiting map[reflect.Type]bool) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		return map[string]any{}
+	}
+
+	if implementsSchemaMarshaler(t) {
+		return map[string]any{"type": SCHEMA_TYPE_STRING}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": SCHEMA_TYPE_BOOLEAN}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": SCHEMA_TYPE_INTEGER}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": SCHEMA_TYPE_NUMBER}
+	case reflect.String:
+		return map[string]any{"type": SCHEMA_TYPE_STRING}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": SCHEMA_TYPE_STRING}
+		}
+		return map[string]any{"type": SCHEMA_TYPE_ARRAY, "items": schemaOfType(t.Elem(), visiting)}
+	case reflect.Map:
+		return map[string]any{"type": SCHEMA_TYPE_OBJECT, "additionalProperties": schemaOfType(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return map[string]any{"type": SCHEMA_TYPE_OBJECT}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		var fields []*schemaField
+		collectSchemaFields(t, visiting, 0, false, &fields)
+
+		properties := map[string]any{}
+		required := []any{}
+		for _, field := range dominantSchemaFields(fields) {
+			properties[field.name] = field.property
+			if field.required {
+				required = append(required, field.name)
+			}
+		}
+
+		return map[string]any{
+			"type":                 SCHEMA_TYPE_OBJECT,
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	default:
+		return map[string]any{}
+	}
+}
+
+var (
+	json_marshaler_type = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	text_marshaler_type = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func implementsSchemaMarshaler(t reflect.Type) bool {
+	pointer := reflect.PointerTo(t)
+	return t.Implements(json_marshaler_type) || t.Implements(text_marshaler_type) ||
+		pointer.Implements(json_marshaler_type) || pointer.Implements(text_marshaler_type)
+}
+
+type schemaField struct {
+	name     string
+	property map[string]any
+	required bool
+	tagged   bool
+	depth    int
+}
+
+func collectSchemaFields(t reflect.Type, visiting map[reflect.Type]bool, depth int, optional bool, fields *[]*schemaField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name := ""
+		omitempty := false
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			parts := strings.Split(tag, ",")
+			name = parts[0]
+			for _, option := range parts[1:] {
+				if option == "omitempty" {
+					omitempty = true
+				}
+			}
+		}
+
+		field_type := field.Type
+		if field.Anonymous && name == "" {
+			embedded := field_type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct && !implementsSchemaMarshaler(embedded) {
+				if !visiting[embedded] {
+					visiting[embedded] = true
+					collectSchemaFields(embedded, visiting, depth+1, optional || field_type.Kind() == reflect.Pointer, fields)
+					delete(visiting, embedded)
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		tagged := name != ""
+		if !tagged {
+			name = field.Name
+		}
+
+		property := schemaOfType(field_type, visiting)
+		required := !optional && !omitempty
+		if field_type.Kind() == reflect.Pointer {
+			if property_type, ok := property["type"].(string); ok {
+				property["type"] = []any{property_type, SCHEMA_TYPE_NULL}
+			}
+			required = false
+		}
+
+		*fields = append(*fields, &schemaField{name: name, property: property, required: required, tagged: tagged, depth: depth})
+	}
+}
+
+func dominantSchemaFields(fields []*schemaField) []*schemaField {
+	by_name := map[string][]*schemaField{}
+	for _, field := range fields {
+		by_name[field.name] = append(by_name[field.name], field)
+	}
+
+	var dominant []*schemaField
+	for _, field := range fields {
+		candidates := by_name[field.name]
+		if candidates == nil {
+			continue
+		}
+		delete(by_name, field.name)
+
+		var winners []*schemaField
+		for _, candidate := range candidates {
+			if len(winners) == 0 || candidate.depth < winners[0].depth {
+				winners = []*schemaField{candidate}
+			} else if candidate.depth == winners[0].depth {
+				winners = append(winners, candidate)
+			}
+		}
+
+		if len(winners) > 1 {
+			var tagged []*schemaField
+			for _, winner := range winners {
+				if winner.tagged {
+					tagged = append(tagged, winner)
+				}
+			}
+			winners = tagged
+		}
+
+		if len(winners) == 1 {
+			dominant = append(dominant, winners[0])
+		}
+	}
+
+	return dominant
+}
+
+func ValidateJSONSchema(schema map[string]any, value any) error {
+	var violations []SchemaViolation
+	validateSchema(schema, value, "$", &violations)
+
+	if len(violations) > 0 {
+		return &SchemaValidationError{Violations: violations}
+	}
+	return nil
+}
+
+func validateSchema(schema map[string]any, value any, path string, violations *[]SchemaViolation) {
+	report := func(constraint string, format string, args ...any) {
+		*violations = append(*violations, SchemaViolation{Path: path, Constraint: constraint, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, schema_type := range types {
+			if matchSchemaType(schema_type, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			report("type", "expected %s, got %s", strings.Join(types, " or "), jsonTypeOf(value))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, option := range enum {
+			if reflect.DeepEqual(normalizeJSONValue(option), value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			report("enum", "value %v is not one of %v", value, enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+
+		for _, name := range schemaStrings(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				*violations = append(*violations, SchemaViolation{Path: path + "." + name, Constraint: "required", Message: "missing required property"})
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if property, ok := properties[name].(map[string]any); ok {
+				validateSchema(property, v[name], path+"."+name, violations)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					*violations = append(*violations, SchemaViolation{Path: path + "." + name, Constraint: "additionalProperties", Message: "unexpected property"})
+				}
+			case map[string]any:
+				validateSchema(additional, v[name], path+"."+name, violations)
+			}
+		}
+	case []any:
+		if minimum, ok := schemaNumber(schema["minItems"]); ok && float64(len(v)) < minimum {
+			report("minItems", "expected at least %v items, got %d", minimum, len(v))
+		}
+		if maximum, ok := schemaNumber(schema["maxItems"]); ok && float64(len(v)) > maximum {
+			report("maxItems", "expected at most %v items, got %d", maximum, len(v))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i), violations)
+			}
+		}
+	case string:
+		length := float64(len([]rune(v)))
+		if minimum, ok := schemaNumber(schema["minLength"]); ok && length < minimum {
+			report("minLength", "expected at least %v characters, got %v", minimum, length)
+		}
+		if maximum, ok := schemaNumber(schema["maxLength"]); ok && length > maximum {
+			report("maxLength", "expected at most %v characters, got %v", maximum, length)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				report("pattern", "invalid pattern %q: %v", pattern, err)
+			} else if !re.MatchString(v) {
+				report("pattern", "value %q does not match pattern %q", v, pattern)
+			}
+		}
+	case float64:
+		if minimum, ok := schemaNumber(schema["minimum"]); ok && v < minimum {
+			report("minimum", "value %v is less than minimum %v", v, minimum)
+		}
+		if maximum, ok := schemaNumber(schema["maximum"]); ok && v > maximum {
+			report("maximum", "value %v is greater than maximum %v", v, maximum)
+		}
+	}
+}
+
+func schemaTypes(schema_type any) []string {
+	switch t := schema_type.(type) {
+	case string:
+		return []string{t}
+	default:
+		return schemaStrings(t)
+	}
+}
+
+func schemaStrings(values any) []string {
+	switch v := values.(type) {
+	case []string:
+		return v
+	case []any:
+		var strs []string
+		for _, value := range v {
+			if str, ok := value.(string); ok {
+				strs = append(strs, str)
+			}
+		}
+		return strs
+	}
+	return nil
+}
+
+func schemaNumber(value any) (float64, bool) {
+	switch v := normalizeJSONValue(value).(type) {
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func normalizeJSONValue(value any) any {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	}
+	return value
+}
+
+func matchSchemaType(schema_type string, value any) bool {
+	switch schema_type {
+	case SCHEMA_TYPE_OBJECT:
+		_, ok := value.(map[string]any)
+		return ok
+	case SCHEMA_TYPE_ARRAY:
+		_, ok := value.([]any)
+		return ok
+	case SCHEMA_TYPE_STRING:
+		_, ok := value.(string)
+		return ok
+	case SCHEMA_TYPE_NUMBER:
+		_, ok := value.(float64)
+		return ok
+	case SCHEMA_TYPE_INTEGER:
+		v, ok := value.(float64)
+		return ok && v == math.Trunc(v)
+	case SCHEMA_TYPE_BOOLEAN:
+		_, ok := value.(bool)
+		return ok
+	case SCHEMA_TYPE_NULL:
+		return value == nil
+	}
+	return true
+}
+
+func jsonTypeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return SCHEMA_TYPE_NULL
+	case map[string]any:
+		return SCHEMA_TYPE_OBJECT
+	case []any:
+		return SCHEMA_TYPE_ARRAY
+	case string:
+		return SCHEMA_TYPE_STRING
+	case bool:
+		return SCHEMA_TYPE_BOOLEAN
+	case float64:
+		if v == math.Trunc(v) {
+			return SCHEMA_TYPE_INTEGER
+		}
+		return SCHEMA_TYPE_NUMBER
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,192 @@
+package deepseek_api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+const OBJECT_CHAT_COMPLETION_CHUNK = "chat.completion.chunk"
+
+var stream_done = []byte("[DONE]")
+
+type ToolCallDelta struct {
+	Index    int64  `json:"index"`
+	Id       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments,omitempty"`
+	} `json:"function"`
+}
+
+type ChatDelta struct {
+	Role             string          `json:"role,omitempty"`
+	Content          string          `json:"content,omitempty"`
+	ReasoningContent string          `json:"reasoning_content,omitempty"`
+	ToolCalls        []ToolCallDelta `json:"tool_calls,omitempty"`
+}
+
+type ChatChunkChoice struct {
+	FinishReason *string   `json:"finish_reason"`
+	Index        int64     `json:"index"`
+	Delta        ChatDelta `json:"delta"`
+	Logprobs     *struct {
+		Content []Content `json:"content"`
+	} `json:"logprobs,omitempty"`
+}
+
+type DeepSeekChatChunk struct {
+	Id                string            `json:"id"`
+	Choices           []ChatChunkChoice `json:"choices"`
+	Created           int64             `json:"created"`
+	Model             string            `json:"model"`
+	SystemFingerprint *string           `json:"system_fingerprint"`
+	Object            string            `json:"object"`
+	Usage             *Usage            `json:"usage,omitempty"`
+}
+
+type DeepSeekStreamReader struct {
+	body     io.ReadCloser
+	reader   *bufio.Reader
+	done     bool
+	on_event func()
+}
+
+func NewDeepSeekStreamReader(body io.ReadCloser) *DeepSeekStreamReader {
+	return &DeepSeekStreamReader{body: body, reader: bufio.NewReader(body)}
+}
+
+func (dsr *DeepSeekStreamReader) Next() ([]byte, error) {
+	if dsr.done {
+		return nil, io.EOF
+	}
+
+	var data []byte
+	for {
+		line, err := dsr.reader.ReadBytes('\n')
+
+		line = bytes.TrimRight(line, "\r\n")
+		if bytes.HasPrefix(line, []byte("data:")) {
+			if len(data) > 0 {
+				data = append(data, '\n')
+			}
+			data = append(data, bytes.TrimPrefix(bytes.TrimPrefix(line, []byte("data:")), []byte(" "))...)
+		}
+
+		if err != nil {
+			if len(data) > 0 {
+				break
+			}
+			if errors.Is(err, io.EOF) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+
+		if len(line) == 0 && len(data) > 0 {
+			break
+		}
+	}
+
+	if bytes.Equal(data, stream_done) {
+		dsr.done = true
+		return nil, io.EOF
+	}
+
+	if dsr.on_event != nil {
+		dsr.on_event()
+	}
+
+	return data, nil
+}
+
+func (dsr *DeepSeekStreamReader) Close() error {
+	return dsr.body.Close()
+}
+
+type DeepSeekChatStream struct {
+	*DeepSeekStreamReader
+}
+
+func (dcs *DeepSeekChatStream) Recv() (*DeepSeekChatChunk, error) {
+	data, err := dcs.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeChatChunk(data)
+}
+
+func decodeChatChunk(data []byte) (*DeepSeekChatChunk, error) {
+	dse_resp := &DeepSeekErrorResponse{}
+	if json.Unmarshal(data, dse_resp) == nil && dse_resp.Error.Message != "" {
+		return nil, dse_resp.DeepSeekResponse()
+	}
+
+	chunk := &DeepSeekChatChunk{}
+	err := json.Unmarshal(data, chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	return chunk, nil
+}
+
+func (dsc *DeepSeekClient) openStream(ctx context.Context, path string, ds_req DeepSeekRequest) (*DeepSeekStreamReader, error) {
+	if !ds_req.StreamModel() {
+		return nil, errors.New("stream must be set to true")
+	}
+
+	ds_req_json, err := json.Marshal(ds_req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, watchdog, err := dsc.sendStream(ctx, http.MethodPost, path, ds_req_json, false)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := NewDeepSeekStreamReader(resp.Body)
+	stream.on_event = watchdog.event
+	return stream, nil
+}
+
+func (dsc *DeepSeekClient) ChatStream(ctx context.Context, dsc_req *DeepSeekChatRequest) (*DeepSeekChatStream, error) {
+	dsc_req, err := dsc.prepareChatRequest(dsc_req)
+	if err != nil {
+		return nil, err
+	}
+	dsc_req.Stream = true
+	if dsc_req.StreamOptions == nil {
+		dsc_req.StreamOptions = &StreamOption{IncludeUsage: true}
+	}
+
+	path := DEFAULT_CHAT_PATH
+	if dsc_req.strictTools() {
+		path = DEFAULT_BETA_CHAT_PATH
+	}
+
+	stream, err := dsc.openStream(ctx, path, dsc_req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeepSeekChatStream{DeepSeekStreamReader: stream}, nil
+}
+
+func (dsc *DeepSeekClient) CompletionsStream(ctx context.Context, dsc_req *DeepSeekCompletionsRequest) (*DeepSeekStreamReader, error) {
+	if dsc_req == nil {
+		return nil, errors.New("completions request cannot be nil")
+	}
+
+	stream_req := *dsc_req
+	stream_req.Stream = true
+
+	return dsc.openStream(ctx, DEFAULT_COMPLETIONS_PATH, &stream_req)
+}
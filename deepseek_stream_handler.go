@@ -0,0 +1,108 @@
+package deepseek_api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+type DeepSeekStreamHandler struct {
+	OnContent       func(delta string)
+	OnReasoning     func(delta string)
+	OnToolCallStart func(id string, name string)
+	OnToolCallArgs  func(id string, delta string)
+	OnContentJSON   func(partial any)
+	OnToolCallJSON  func(id string, partial any)
+	OnFinish        func(finish_reason string, usage *Usage)
+	OnError         func(err error)
+}
+
+func (dsh *DeepSeekStreamHandler) fail(err error) error {
+	if dsh.OnError != nil {
+		dsh.OnError(err)
+	}
+	return err
+}
+
+func (dsh *DeepSeekStreamHandler) Handle(stream *DeepSeekChatStream) error {
+	tool_call_ids := map[int64]string{}
+	content_json := NewPartialJSONParser()
+	tool_call_json := NewToolCallAccumulator()
+
+	var finish_reason string
+	var usage *Usage
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return dsh.fail(err)
+		}
+
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.ReasoningContent != "" && dsh.OnReasoning != nil {
+				dsh.OnReasoning(choice.Delta.ReasoningContent)
+			}
+
+			if choice.Delta.Content != "" && dsh.OnContent != nil {
+				dsh.OnContent(choice.Delta.Content)
+			}
+
+			if choice.Delta.Content != "" && dsh.OnContentJSON != nil {
+				dsh.OnContentJSON(content_json.Write(choice.Delta.Content))
+			}
+
+			for _, tool_call := range choice.Delta.ToolCalls {
+				id, ok := tool_call_ids[tool_call.Index]
+				if !ok || (tool_call.Id != "" && tool_call.Id != id) {
+					id = tool_call.Id
+					tool_call_ids[tool_call.Index] = id
+					if dsh.OnToolCallStart != nil {
+						dsh.OnToolCallStart(id, tool_call.Function.Name)
+					}
+				}
+
+				if tool_call.Function.Arguments != "" && dsh.OnToolCallArgs != nil {
+					dsh.OnToolCallArgs(id, tool_call.Function.Arguments)
+				}
+
+				if tool_call.Function.Arguments != "" && dsh.OnToolCallJSON != nil {
+					for _, call := range tool_call_json.Add([]ToolCallDelta{tool_call}) {
+						dsh.OnToolCallJSON(id, call.Partial)
+					}
+				}
+			}
+
+			if choice.FinishReason != nil {
+				finish_reason = *choice.FinishReason
+			}
+		}
+	}
+
+	if dsh.OnFinish != nil {
+		dsh.OnFinish(finish_reason, usage)
+	}
+
+	return nil
+}
+
+func (dsh *DeepSeekStreamHandler) StreamDoEvent() StreamDoEvent {
+	return func(response *http.Response, args ...any) error {
+		return dsh.Handle(&DeepSeekChatStream{DeepSeekStreamReader: NewDeepSeekStreamReader(response.Body)})
+	}
+}
+
+func (dsc *DeepSeekClient) StreamChat(ctx context.Context, dsc_req *DeepSeekChatRequest, handler *DeepSeekStreamHandler) error {
+	stream, err := dsc.ChatStream(ctx, dsc_req)
+	if err != nil {
+		return handler.fail(err)
+	}
+	defer stream.Close()
+
+	return handler.Handle(stream)
+}
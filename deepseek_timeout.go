@@ -0,0 +1,265 @@
+package deepseek_api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	STREAM_TIMEOUT_CONNECT     = "connect"
+	STREAM_TIMEOUT_FIRST_TOKEN = "first token"
+	STREAM_TIMEOUT_IDLE        = "idle"
+	STREAM_TIMEOUT_TOTAL       = "total"
+)
+
+var (
+	ErrStreamConnectTimeout    = &StreamTimeoutError{Phase: STREAM_TIMEOUT_CONNECT}
+	ErrStreamFirstTokenTimeout = &StreamTimeoutError{Phase: STREAM_TIMEOUT_FIRST_TOKEN}
+	ErrStreamIdleTimeout       = &StreamTimeoutError{Phase: STREAM_TIMEOUT_IDLE}
+	ErrStreamTotalTimeout      = &StreamTimeoutError{Phase: STREAM_TIMEOUT_TOTAL}
+)
+
+type StreamTimeouts struct {
+	Connect    time.Duration
+	FirstToken time.Duration
+	Idle       time.Duration
+	Total      time.Duration
+}
+
+type StreamTimeoutError struct {
+	Phase    string
+	Duration time.Duration
+}
+
+func (e *StreamTimeoutError) Error() string {
+	if e.Duration <= 0 {
+		return fmt.Sprintf("stream %s timeout", e.Phase)
+	}
+	return fmt.Sprintf("stream %s timeout after %s", e.Phase, e.Duration)
+}
+
+func (e *StreamTimeoutError) Is(target error) bool {
+	t, ok := target.(*StreamTimeoutError)
+	return ok && t.Phase == e.Phase
+}
+
+func (e *StreamTimeoutError) Timeout() bool {
+	return true
+}
+
+type streamRequestKey struct{}
+
+type streamWatchdogKey struct{}
+
+type streamWatchdog struct {
+	mutex    sync.Mutex
+	timeouts StreamTimeouts
+	cancel   context.CancelFunc
+	err      *StreamTimeoutError
+	stopped  bool
+
+	breaker *CircuitBreaker
+	settled bool
+
+	connect     *time.Timer
+	first_token *time.Timer
+	idle        *time.Timer
+	total       *time.Timer
+}
+
+func (dsc *DeepSeekClient) newStreamWatchdog(ctx context.Context) (context.Context, *streamWatchdog) {
+	ctx = context.WithValue(ctx, streamRequestKey{}, true)
+	if dsc.stream_timeouts == (StreamTimeouts{}) {
+		return ctx, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	sw := &streamWatchdog{timeouts: dsc.stream_timeouts, cancel: cancel}
+
+	sw.mutex.Lock()
+	sw.total = sw.after(sw.timeouts.Total, STREAM_TIMEOUT_TOTAL)
+	sw.connect = sw.after(sw.timeouts.Connect, STREAM_TIMEOUT_CONNECT)
+	sw.first_token = sw.after(sw.timeouts.FirstToken, STREAM_TIMEOUT_FIRST_TOKEN)
+	sw.mutex.Unlock()
+
+	return context.WithValue(ctx, streamWatchdogKey{}, sw), sw
+}
+
+func streamWatchdogFrom(ctx context.Context) *streamWatchdog {
+	sw, _ := ctx.Value(streamWatchdogKey{}).(*streamWatchdog)
+	return sw
+}
+
+func (sw *streamWatchdog) after(timeout time.Duration, phase string) *time.Timer {
+	if timeout <= 0 {
+		return nil
+	}
+
+	return time.AfterFunc(timeout, func() {
+		sw.mutex.Lock()
+		fired := sw.err == nil && !sw.stopped
+		if fired {
+			sw.err = &StreamTimeoutError{Phase: phase, Duration: timeout}
+		}
+		breaker := sw.breaker
+		if !fired {
+			breaker = nil
+		}
+		sw.settled = true
+		sw.mutex.Unlock()
+
+		sw.cancel()
+		if breaker != nil {
+			breaker.Failure()
+		}
+	})
+}
+
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
+
+func (sw *streamWatchdog) connected() {
+	if sw == nil {
+		return
+	}
+
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+
+	stopTimer(sw.connect)
+}
+
+func (sw *streamWatchdog) settle(breaker *CircuitBreaker) {
+	sw.mutex.Lock()
+	sw.breaker = breaker
+	timed_out := sw.err != nil
+	sw.mutex.Unlock()
+
+	if timed_out {
+		breaker.Failure()
+	}
+}
+
+func (sw *streamWatchdog) event() {
+	if sw == nil {
+		return
+	}
+
+	sw.mutex.Lock()
+	if sw.stopped {
+		sw.mutex.Unlock()
+		return
+	}
+
+	var breaker *CircuitBreaker
+	if !sw.settled {
+		breaker = sw.breaker
+		sw.settled = true
+	}
+
+	stopTimer(sw.first_token)
+	if sw.idle == nil {
+		sw.idle = sw.after(sw.timeouts.Idle, STREAM_TIMEOUT_IDLE)
+	} else {
+		sw.idle.Reset(sw.timeouts.Idle)
+	}
+	sw.mutex.Unlock()
+
+	if breaker != nil {
+		breaker.Success()
+	}
+}
+
+func (sw *streamWatchdog) error(err error) error {
+	if sw == nil || err == nil {
+		return err
+	}
+
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+
+	if sw.err != nil {
+		return sw.err
+	}
+	return err
+}
+
+func (sw *streamWatchdog) stop() {
+	if sw == nil {
+		return
+	}
+
+	sw.mutex.Lock()
+	sw.stopped = true
+	stopTimer(sw.connect)
+	stopTimer(sw.first_token)
+	stopTimer(sw.idle)
+	stopTimer(sw.total)
+
+	breaker := sw.breaker
+	if sw.settled {
+		breaker = nil
+	}
+	sw.settled = true
+	sw.mutex.Unlock()
+
+	sw.cancel()
+	if breaker != nil {
+		breaker.release()
+	}
+}
+
+type watchdogBody struct {
+	io.ReadCloser
+	watchdog    *streamWatchdog
+	track_reads bool
+}
+
+func (wb *watchdogBody) Read(p []byte) (int, error) {
+	n, err := wb.ReadCloser.Read(p)
+	if n > 0 && wb.track_reads {
+		wb.watchdog.event()
+	}
+	return n, wb.watchdog.error(err)
+}
+
+func (wb *watchdogBody) Close() error {
+	err := wb.ReadCloser.Close()
+	wb.watchdog.stop()
+	return err
+}
+
+func (dsc *DeepSeekClient) sendStream(ctx context.Context, method string, path string, body []byte, track_reads bool) (*http.Response, *streamWatchdog, error) {
+	ctx, watchdog := dsc.newStreamWatchdog(ctx)
+
+	resp, err := dsc.send(ctx, method, path, body)
+	if err != nil {
+		err = watchdog.error(err)
+		watchdog.stop()
+		return nil, nil, err
+	}
+	watchdog.connected()
+
+	if watchdog != nil {
+		resp.Body = &watchdogBody{ReadCloser: resp.Body, watchdog: watchdog, track_reads: track_reads}
+	}
+
+	return resp, watchdog, nil
+}
+
+func (dsc *DeepSeekClient) httpClient(ctx context.Context) *http.Client {
+	if ctx.Value(streamRequestKey{}) == nil || dsc.stream_timeouts == (StreamTimeouts{}) {
+		return dsc.http_client
+	}
+
+	stream_client := *dsc.http_client
+	stream_client.Timeout = 0
+	return &stream_client
+}
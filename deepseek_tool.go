@@ -0,0 +1,152 @@
+package deepseek_api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	TOOL_TYPE_FUNCTION = "function"
+
+	tool_choice_function_prefix = "\x00function:"
+)
+
+// ToolChoice is either a mode (none, auto or required) or, when built with
+// NamedToolChoice, a specific function. Named choices carry an internal marker
+// that cannot appear in a function name, so they never collide with a mode.
+type ToolChoice string
+
+func NamedToolChoice(name string) ToolChoice {
+	return ToolChoice(tool_choice_function_prefix + name)
+}
+
+func (tc ToolChoice) FunctionName() string {
+	if !strings.HasPrefix(string(tc), tool_choice_function_prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(string(tc), tool_choice_function_prefix)
+}
+
+type namedToolChoice struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name string `json:"name"`
+	} `json:"function"`
+}
+
+func (tc ToolChoice) String() string {
+	if name := tc.FunctionName(); name != "" {
+		return TOOL_TYPE_FUNCTION + ":" + name
+	}
+	return string(tc)
+}
+
+func (tc ToolChoice) MarshalJSON() ([]byte, error) {
+	name := tc.FunctionName()
+	if name == "" {
+		return json.Marshal(string(tc))
+	}
+
+	named := namedToolChoice{Type: TOOL_TYPE_FUNCTION}
+	named.Function.Name = name
+	return json.Marshal(named)
+}
+
+func (tc *ToolChoice) UnmarshalJSON(data []byte) error {
+	var mode string
+	if err := json.Unmarshal(data, &mode); err == nil {
+		*tc = ToolChoice(mode)
+		return nil
+	}
+
+	var named namedToolChoice
+	if err := json.Unmarshal(data, &named); err != nil {
+		return err
+	}
+
+	if named.Type != TOOL_TYPE_FUNCTION || named.Function.Name == "" {
+		return errors.New("tool_choice object must name a function")
+	}
+
+	*tc = NamedToolChoice(named.Function.Name)
+	return nil
+}
+
+func (dsr *DeepSeekChatRequest) strictTools() bool {
+	for _, tool := range dsr.Tools {
+		if tool.Function.Strict {
+			return true
+		}
+	}
+	return false
+}
+
+func (dsr *DeepSeekChatRequest) toolErrors() []*FieldError {
+	var errs []*FieldError
+
+	for i, tool := range dsr.Tools {
+		if tool.Function.Name == "" {
+			field := fmt.Sprintf("tools[%d].function.name", i)
+			errs = append(errs, newFieldError(field, CONSTRAINT_REQUIRED, tool.Function.Name, field+" must be set"))
+		}
+
+		if tool.Function.Strict {
+			errs = append(errs, strictSchemaErrors(fmt.Sprintf("tools[%d].function.parameters", i), tool.Function.Parameters)...)
+		}
+	}
+
+	return errs
+}
+
+func (dsr *DeepSeekChatRequest) hasTool(name string) bool {
+	for _, tool := range dsr.Tools {
+		if tool.Function.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func strictSchemaErrors(path string, schema map[string]any) []*FieldError {
+	var errs []*FieldError
+
+	if schema == nil {
+		return nil
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 && types[0] == SCHEMA_TYPE_OBJECT {
+		if additional, ok := schema["additionalProperties"].(bool); !ok || additional {
+			errs = append(errs, newFieldError(path+".additionalProperties", CONSTRAINT_STRICT, schema["additionalProperties"], path+" must set additionalProperties to false in strict mode"))
+		}
+
+		properties, _ := schema["properties"].(map[string]any)
+		required := map[string]bool{}
+		for _, name := range schemaStrings(schema["required"]) {
+			required[name] = true
+		}
+
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if !required[name] {
+				errs = append(errs, newFieldError(path+".required", CONSTRAINT_STRICT, name, fmt.Sprintf("%s.%s must be required in strict mode", path, name)))
+			}
+			if property_schema, ok := properties[name].(map[string]any); ok {
+				errs = append(errs, strictSchemaErrors(path+"."+name, property_schema)...)
+			}
+		}
+	}
+
+	if items, ok := schema["items"].(map[string]any); ok {
+		errs = append(errs, strictSchemaErrors(path+"[]", items)...)
+	}
+
+	return errs
+}